@@ -0,0 +1,222 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Age is a parsed GEDCOM age value such as "> 23y 4m" or "CHILD".
+type Age struct {
+	Qualifier string // "<", ">" or empty
+	Years     int
+	Months    int
+	Days      int
+	Keyword   string // CHILD, INFANT or STILLBORN
+}
+
+// ParseAge parses a GEDCOM age value. Weeks, allowed by GEDCOM 7.0, are
+// converted to days and a bare number is taken to be years.
+func ParseAge(s string) (Age, error) {
+	var a Age
+
+	v := strings.ToUpper(strings.TrimSpace(s))
+	switch v {
+	case "":
+		return a, fmt.Errorf("empty age")
+	case "CHILD", "INFANT", "STILLBORN":
+		a.Keyword = v
+		return a, nil
+	}
+
+	if v[0] == '<' || v[0] == '>' {
+		a.Qualifier = v[:1]
+		v = strings.TrimSpace(v[1:])
+	}
+
+	fields := strings.Fields(v)
+	if len(fields) == 0 {
+		return a, fmt.Errorf("age %q has no value", s)
+	}
+
+	if len(fields) == 1 {
+		if n, err := strconv.Atoi(fields[0]); err == nil && n >= 0 {
+			a.Years = n
+			return a, nil
+		}
+	}
+
+	for _, f := range fields {
+		if len(f) < 2 {
+			return a, fmt.Errorf("age %q is not in a recognized form", s)
+		}
+		n, err := strconv.Atoi(f[:len(f)-1])
+		if err != nil || n < 0 {
+			return a, fmt.Errorf("age %q is not in a recognized form", s)
+		}
+		switch f[len(f)-1] {
+		case 'Y':
+			a.Years += n
+		case 'M':
+			a.Months += n
+		case 'W':
+			a.Days += n * 7
+		case 'D':
+			a.Days += n
+		default:
+			return a, fmt.Errorf("age %q is not in a recognized form", s)
+		}
+	}
+
+	return a, nil
+}
+
+// String returns the GEDCOM form of the age.
+func (a Age) String() string {
+	if a.Keyword != "" {
+		return a.Keyword
+	}
+
+	var parts []string
+	if a.Qualifier != "" {
+		parts = append(parts, a.Qualifier)
+	}
+	if a.Years != 0 {
+		parts = append(parts, fmt.Sprintf("%dy", a.Years))
+	}
+	if a.Months != 0 {
+		parts = append(parts, fmt.Sprintf("%dm", a.Months))
+	}
+	if a.Days != 0 || (a.Years == 0 && a.Months == 0) {
+		parts = append(parts, fmt.Sprintf("%dd", a.Days))
+	}
+	return strings.Join(parts, " ")
+}
+
+// span returns the youngest and oldest the age may mean, each as a number of
+// years, months and days. An age stated to a unit covers everything up to the
+// next whole unit, so "23y" spans from 23 years to just under 24. The oldest
+// bound is nil when the age is unbounded, as with "> 23y".
+func (a Age) span() (min Age, max *Age) {
+	switch a.Keyword {
+	case "STILLBORN":
+		return Age{}, &Age{}
+	case "INFANT":
+		return Age{}, &Age{Days: 364}
+	case "CHILD":
+		return Age{}, &Age{Years: 7, Months: 11, Days: 30}
+	}
+
+	exact := Age{Years: a.Years, Months: a.Months, Days: a.Days}
+	upper := exact
+	switch {
+	case a.Days != 0:
+	case a.Months != 0:
+		upper.Months++
+		upper.Days--
+	default:
+		upper.Years++
+		upper.Days--
+	}
+
+	switch a.Qualifier {
+	case "<":
+		below := exact
+		below.Days--
+		return Age{}, &below
+	case ">":
+		return exact, nil
+	}
+	return exact, &upper
+}
+
+// subtractAge returns t moved back by the years, months and days of a.
+func subtractAge(t time.Time, a Age) time.Time {
+	return t.AddDate(-a.Years, -a.Months, -a.Days)
+}
+
+// ageBetween returns the whole years, months and days from one date to a later one.
+func ageBetween(from time.Time, to time.Time) Age {
+	y := to.Year() - from.Year()
+	m := int(to.Month()) - int(from.Month())
+	d := to.Day() - from.Day()
+	if d < 0 {
+		m--
+		// days remaining in the month before to
+		d += time.Date(to.Year(), to.Month(), 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+	if m < 0 {
+		y--
+		m += 12
+	}
+	return Age{Years: y, Months: m, Days: d}
+}
+
+// AgeAt returns the youngest and oldest ind could have been at the time of
+// event e, based on the ranges covered by the dates of ind's birth and of e.
+func AgeAt(ind *IndividualRecord, e *EventRecord) (min Age, max Age, err error) {
+	birth := ind.birth()
+	if birth == nil || birth.Date == "" {
+		return min, max, fmt.Errorf("individual %s has no birth date", ind.Xref)
+	}
+	b, err := ParseDateRange(birth.Date)
+	if err != nil {
+		return min, max, err
+	}
+	if e.Date == "" {
+		return min, max, fmt.Errorf("%s event has no date", e.Tag)
+	}
+	when, err := ParseDateRange(e.Date)
+	if err != nil {
+		return min, max, err
+	}
+
+	if b.IsOpen() || when.IsOpen() {
+		return min, max, fmt.Errorf("age of %s at %s event is unbounded", ind.Xref, e.Tag)
+	}
+	if when.Latest.Before(b.Earliest) {
+		return min, max, fmt.Errorf("%s event precedes the birth of %s", e.Tag, ind.Xref)
+	}
+
+	if !when.Earliest.Before(b.Latest) {
+		min = ageBetween(b.Latest, when.Earliest)
+	}
+	max = ageBetween(b.Earliest, when.Latest)
+	return min, max, nil
+}
+
+// EstimateBirth returns the range of birth dates consistent with a person
+// having the given age on the given date.
+func EstimateBirth(age Age, date string) (DateRange, error) {
+	when, err := ParseDateRange(date)
+	if err != nil {
+		return DateRange{}, err
+	}
+
+	min, max := age.span()
+
+	var r DateRange
+	if !when.Latest.IsZero() {
+		r.Latest = subtractAge(when.Latest, min)
+	}
+	if !when.Earliest.IsZero() && max != nil {
+		r.Earliest = subtractAge(when.Earliest, *max)
+	}
+	return r, nil
+}
+
+// birth returns the individual's birth event, if any.
+func (i *IndividualRecord) birth() *EventRecord {
+	for _, e := range i.Event {
+		if e.Tag == "BIRT" {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+
+	var ageTests = []struct {
+		in      string
+		correct Age
+		out     string
+	}{
+		{"42y", Age{Years: 42}, "42y"},
+		{"> 23y 4m", Age{Qualifier: ">", Years: 23, Months: 4}, "> 23y 4m"},
+		{"<1y", Age{Qualifier: "<", Years: 1}, "< 1y"},
+		{"42y 6m 9d", Age{Years: 42, Months: 6, Days: 9}, "42y 6m 9d"},
+		{"6m 9d", Age{Months: 6, Days: 9}, "6m 9d"},
+		{"3w", Age{Days: 21}, "21d"},
+		{"42", Age{Years: 42}, "42y"},
+		{"CHILD", Age{Keyword: "CHILD"}, "CHILD"},
+		{"stillborn", Age{Keyword: "STILLBORN"}, "STILLBORN"},
+	}
+
+	for _, tt := range ageTests {
+		result, err := ParseAge(tt.in)
+		if err != nil {
+			t.Errorf("%q gave error %v, expected %v", tt.in, err, tt.correct)
+			continue
+		}
+		if result != tt.correct {
+			t.Errorf("%q => %+v, want %+v", tt.in, result, tt.correct)
+		}
+		if result.String() != tt.out {
+			t.Errorf("%q => %q, want %q", tt.in, result.String(), tt.out)
+		}
+	}
+
+	for _, in := range []string{"", "y", "42x", ">", "old", "-5", "-3y"} {
+		if result, err := ParseAge(in); err == nil {
+			t.Errorf("%q => %+v, expected error", in, result)
+		}
+	}
+
+	ages := g.Family[0].Event
+	for _, e := range ages {
+		for _, si := range e.SpouseInfo {
+			if _, err := ParseAge(si.Age); si.Age != "" && err != nil {
+				t.Errorf("%s %s age %q gave error %v", e.Tag, si.Spouse, si.Age, err)
+			}
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	var rangeTests = []struct {
+		in      string
+		correct DateRange
+	}{
+		{"1850", DateRange{day(1850, time.January, 1), day(1850, time.December, 31)}},
+		{"FEB 1852", DateRange{day(1852, time.February, 1), day(1852, time.February, 29)}},
+		{"3 MAR 1850", DateRange{day(1850, time.March, 3), day(1850, time.March, 3)}},
+		{"ABT 1850", DateRange{day(1848, time.January, 1), day(1852, time.December, 31)}},
+		{"BEF 1850", DateRange{time.Time{}, day(1850, time.December, 31)}},
+		{"AFT MAR 1850", DateRange{day(1850, time.March, 1), time.Time{}}},
+		{"BET 1850 AND 1855", DateRange{day(1850, time.January, 1), day(1855, time.December, 31)}},
+		{"FROM 1 JAN 1900 TO 2 JAN 1900", DateRange{day(1900, time.January, 1), day(1900, time.January, 2)}},
+		{"@#DGREGORIAN@ 1750/51", DateRange{day(1750, time.January, 1), day(1751, time.December, 31)}},
+		{"INT 1850 (about then)", DateRange{day(1850, time.January, 1), day(1850, time.December, 31)}},
+	}
+
+	for _, tt := range rangeTests {
+		result, err := ParseDateRange(tt.in)
+		if err != nil {
+			t.Errorf("%q gave error %v", tt.in, err)
+		} else if result != tt.correct {
+			t.Errorf("%q => %v, want %v", tt.in, result, tt.correct)
+		}
+	}
+
+	for _, in := range []string{"", "(sometime)", "30 FEB 1850", "BET 1850", "BET 1860 AND 1850"} {
+		if result, err := ParseDateRange(in); err == nil {
+			t.Errorf("%q => %v, expected error", in, result)
+		}
+	}
+}
+
+func TestAgeAt(t *testing.T) {
+
+	ind := &IndividualRecord{
+		Xref: "I1",
+		Event: []*EventRecord{
+			{Tag: "BIRT", Date: "10 JUN 1850"},
+		},
+	}
+
+	min, max, err := AgeAt(ind, &EventRecord{Tag: "CENS", Date: "1881"})
+	if err != nil {
+		t.Fatalf("AgeAt gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Youngest age at census", "30y 6m 22d", min.String()},
+		{"Oldest age at census", "31y 6m 21d", max.String()},
+	}.run(t)
+
+	if _, _, err := AgeAt(ind, &EventRecord{Tag: "CENS", Date: "1840"}); err == nil {
+		t.Errorf("AgeAt for event before birth gave no error, expected error")
+	}
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	var estimateTests = []struct {
+		age     string
+		date    string
+		correct DateRange
+	}{
+		{"23y", "3 APR 1881", DateRange{day(1857, time.April, 4), day(1858, time.April, 3)}},
+		{"> 60y", "1881", DateRange{time.Time{}, day(1821, time.December, 31)}},
+		{"< 1y", "1 JUN 1881", DateRange{day(1880, time.June, 2), day(1881, time.June, 1)}},
+		{"STILLBORN", "1 JUN 1881", DateRange{day(1881, time.June, 1), day(1881, time.June, 1)}},
+	}
+
+	for _, tt := range estimateTests {
+		a, _ := ParseAge(tt.age)
+		result, err := EstimateBirth(a, tt.date)
+		if err != nil {
+			t.Errorf("%q at %q gave error %v", tt.age, tt.date, err)
+		} else if result != tt.correct {
+			t.Errorf("%q at %q => %v, want %v", tt.age, tt.date, result, tt.correct)
+		}
+	}
+}
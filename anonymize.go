@@ -0,0 +1,557 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AnonymizeOptions controls the pseudonyms and date shift chosen by
+// Anonymize.
+type AnonymizeOptions struct {
+	Seed      int64 // seeds the pseudonyms and date shift; zero for a random seed
+	DateShift int   // days by which to move dates; zero for a random shift of one to ten years
+}
+
+// codedTags are tags whose values are codes rather than personal data,
+// and are kept as they are.
+var codedTags = map[string]bool{
+	"AGE": true, "CHAR": true, "FORM": true, "GEDC": true, "LANG": true,
+	"MEDI": true, "NCHI": true, "PEDI": true, "QUAY": true, "RESN": true,
+	"ROLE": true, "SEX": true, "STAT": true, "TIME": true, "VERS": true,
+}
+
+var (
+	anonymizeDatePattern = regexp.MustCompile(`(?i)\b(?:(\d{1,2})\s+)?(?:(JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC|TSH|CSH|KSL|TVT|SHV|ADR|ADS|NSN|IYR|SVN|TMZ|AAV|ELL|VEND|BRUM|FRIM|NIVO|PLUV|VENT|GERM|FLOR|PRAI|MESS|THER|FRUC|COMP)\s+)?(\d{1,4})(?:/(\d{1,2}))?\b`)
+	dateSeparator        = regexp.MustCompile(`\s+`)
+	coordinatePattern    = regexp.MustCompile(`^(\s*[NSEWnsew]?[-+]?)(\d+)(?:\.(\d+))?(\s*)$`)
+	monthNames           = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+)
+
+// Anonymize copies the GEDCOM file read from r to w with the personal data
+// replaced, so that it can be shared when reporting a problem. Every word of
+// names, places, addresses, notes and other text is replaced by a pseudonym
+// of the same length and case made of random letters and digits, the same
+// word always getting the same pseudonym. Letters and digits outside ASCII
+// are replaced by others of the same script and the same length in UTF-8.
+// Dates are moved by the same number of days, or for dates without a day or
+// month by the nearest number of months or years. Date phrases are treated
+// as text. Latitudes and longitudes are moved by up to a degree, keeping
+// their hemisphere and the number of their digits.
+//
+// Everything else is copied byte for byte: levels, xrefs, pointers, tags,
+// the values of coded tags such as SEX and CHAR, the header apart from its
+// notes, copyright and file name, whitespace, punctuation, line endings,
+// byte order marks and bytes that are not UTF-8, so that the copy reproduces
+// problems with the structure or encoding of the original. Lines that cannot
+// be parsed are treated as text.
+func Anonymize(r io.Reader, w io.Writer, opts *AnonymizeOptions) error {
+	if opts == nil {
+		opts = &AnonymizeOptions{}
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = timeNow().UnixNano()
+	}
+	a := &anonymizer{
+		rng:        rand.New(rand.NewSource(seed)),
+		pseudonyms: make(map[string]string),
+		used:       make(map[string]bool),
+		letters:    make(map[letterClass][]rune),
+		shift:      opts.DateShift,
+	}
+	if a.shift == 0 {
+		a.shift = 366 + a.rng.Intn(3287)
+		if a.rng.Intn(2) == 0 {
+			a.shift = -a.shift
+		}
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), 1<<20)
+	s.Split(scanLinesWithEndings)
+	bw := bufio.NewWriter(w)
+
+	var path []string
+	for s.Scan() {
+		line := s.Bytes()
+		content := bytes.TrimRight(line, "\r\n")
+		if _, err := bw.Write(a.line(content, &path)); err != nil {
+			return err
+		}
+		if _, err := bw.Write(line[len(content):]); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// anonymizer holds the state of Anonymize.
+type anonymizer struct {
+	rng        *rand.Rand
+	pseudonyms map[string]string // by lower case word
+	used       map[string]bool   // pseudonyms already given
+	letters    map[letterClass][]rune
+	shift      int
+}
+
+// letterClass is a kind of letter or digit that a rune outside ASCII may be
+// replaced by.
+type letterClass struct {
+	script    *unicode.RangeTable
+	category  byte // 'l' lower case, 'u' upper case or 'o' other letter
+	size      int  // length in UTF-8
+	upperSize int  // length in UTF-8 of the upper case of a lower case letter
+}
+
+// line returns the anonymized content of a line, with path the tags leading
+// to the previous line.
+func (a *anonymizer) line(content []byte, path *[]string) []byte {
+	i := 0
+	for i < len(content) && (isSpace(content[i]) || isByteOrderMark(content[i])) {
+		i++
+	}
+	start := i
+	for i < len(content) && content[i] >= '0' && content[i] <= '9' {
+		i++
+	}
+	if i == start || i == len(content) || content[i] != ' ' {
+		return a.text(content)
+	}
+	level, _ := strconv.Atoi(string(content[start:i]))
+	for i < len(content) && content[i] == ' ' {
+		i++
+	}
+	if i < len(content) && content[i] == '@' {
+		end := bytes.IndexByte(content[i+1:], '@')
+		if end < 0 {
+			return a.text(content)
+		}
+		i += end + 2
+		for i < len(content) && content[i] == ' ' {
+			i++
+		}
+	}
+	tagStart := i
+	for i < len(content) && content[i] != ' ' {
+		i++
+	}
+	tag := string(content[tagStart:i])
+	if tag == "" {
+		return a.text(content)
+	}
+
+	if level < len(*path) {
+		*path = (*path)[:level]
+	}
+	*path = append(*path, tag)
+
+	if i == len(content) {
+		return content
+	}
+	i++
+	out := append([]byte(nil), content[:i]...)
+	return append(out, a.value(content[i:], *path)...)
+}
+
+// value returns the anonymized value of the line at path.
+func (a *anonymizer) value(v []byte, path []string) []byte {
+	tag := path[len(path)-1]
+	trimmed := bytes.TrimSpace(v)
+	switch {
+	case codedTags[tag] || string(trimmed) == "Y" || isPointerValue(trimmed):
+		return v
+	case path[0] == "HEAD" && !containsAny(path, "NOTE", "COPR", "FILE"):
+		return v
+	case tag == "DATE" || tag == "SDATE":
+		return a.date(v)
+	case tag == "LATI":
+		return a.coordinate(v, 90)
+	case tag == "LONG":
+		return a.coordinate(v, 180)
+	}
+	return a.text(v)
+}
+
+// text returns v with every word replaced by its pseudonym. Pointers within
+// the text are kept.
+func (a *anonymizer) text(v []byte) []byte {
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); {
+		if end := bytes.IndexByte(v[i+1:], '@'); v[i] == '@' && end > 0 && isPointerValue(v[i:i+end+2]) {
+			out = append(out, v[i:i+end+2]...)
+			i += end + 2
+			continue
+		}
+		n, word := wordRune(v[i:])
+		if !word {
+			out = append(out, v[i:i+n]...)
+			i += n
+			continue
+		}
+		j := i + n
+		for j < len(v) {
+			n, word := wordRune(v[j:])
+			if !word {
+				break
+			}
+			j += n
+		}
+		out = append(out, a.pseudonym(v[i:j])...)
+		i = j
+	}
+	return out
+}
+
+// pseudonym returns the pseudonym of word, in the same case as word.
+func (a *anonymizer) pseudonym(word []byte) []byte {
+	lower := make([]byte, 0, len(word))
+	for i := 0; i < len(word); {
+		r, n := utf8.DecodeRune(word[i:])
+		if r == utf8.RuneError && n == 1 {
+			lower = append(lower, word[i])
+		} else {
+			lower = append(lower, string(lowerRune(r))...)
+		}
+		i += n
+	}
+	key := string(lower)
+	p, ok := a.pseudonyms[key]
+	if !ok {
+		for try := 0; try < 10 && (p == "" || a.used[p]); try++ {
+			b := make([]byte, 0, len(key))
+			for i := 0; i < len(key); {
+				r, n := utf8.DecodeRuneInString(key[i:])
+				switch {
+				case r >= 'a' && r <= 'z':
+					b = append(b, byte('a'+a.rng.Intn(26)))
+				case r >= '0' && r <= '9':
+					b = append(b, byte('0'+a.rng.Intn(10)))
+				case r >= utf8.RuneSelf && !(r == utf8.RuneError && n == 1):
+					b = append(b, string(a.replaceRune(r))...)
+				default:
+					b = append(b, key[i:i+n]...)
+				}
+				i += n
+			}
+			p = string(b)
+		}
+		a.pseudonyms[key] = p
+		a.used[p] = true
+	}
+
+	out := make([]byte, 0, len(word))
+	for i, j := 0, 0; i < len(word); {
+		r, n := utf8.DecodeRune(word[i:])
+		q, m := utf8.DecodeRuneInString(p[j:])
+		if r == utf8.RuneError && n == 1 || q == utf8.RuneError && m == 1 || lowerRune(r) == r {
+			out = append(out, p[j:j+m]...)
+		} else {
+			out = append(out, string(unicode.ToUpper(q))...)
+		}
+		i, j = i+n, j+m
+	}
+	return out
+}
+
+// lowerRune returns the lower case of r, or r itself if r has no lower case
+// of the same length in UTF-8 whose upper case is r.
+func lowerRune(r rune) rune {
+	l := unicode.ToLower(r)
+	if unicode.ToUpper(l) != r || utf8.RuneLen(l) != utf8.RuneLen(r) {
+		return r
+	}
+	return l
+}
+
+// replaceRune returns a random letter to replace r, of the same script, kind
+// and length in UTF-8, and for a lower case letter with an upper case of the
+// same length as the upper case of r, or a random digit of the same set of
+// ten as r. Runes without a script or that are neither letters nor digits
+// are kept.
+func (a *anonymizer) replaceRune(r rune) rune {
+	class := letterClass{size: utf8.RuneLen(r)}
+	switch {
+	case unicode.IsDigit(r):
+		return digitZero(r) + rune(a.rng.Intn(10))
+	case unicode.IsLower(r):
+		class.category = 'l'
+		class.upperSize = utf8.RuneLen(unicode.ToUpper(r))
+	case unicode.IsUpper(r):
+		class.category = 'u'
+	case unicode.IsLetter(r):
+		class.category = 'o'
+	default:
+		return r
+	}
+	for _, script := range unicode.Scripts {
+		if unicode.Is(script, r) {
+			class.script = script
+			break
+		}
+	}
+	if class.script == nil {
+		return r
+	}
+
+	letters, ok := a.letters[class]
+	if !ok {
+		add := func(lo, hi, stride uint32) {
+			for c := rune(lo); c <= rune(hi); c += rune(stride) {
+				if class.matches(c) {
+					letters = append(letters, c)
+				}
+			}
+		}
+		for _, r16 := range class.script.R16 {
+			add(uint32(r16.Lo), uint32(r16.Hi), uint32(r16.Stride))
+		}
+		for _, r32 := range class.script.R32 {
+			add(r32.Lo, r32.Hi, r32.Stride)
+		}
+		a.letters[class] = letters
+	}
+	if len(letters) == 0 {
+		return r
+	}
+	return letters[a.rng.Intn(len(letters))]
+}
+
+// matches reports whether c is of class, apart from its script.
+func (class letterClass) matches(c rune) bool {
+	if utf8.RuneLen(c) != class.size {
+		return false
+	}
+	switch class.category {
+	case 'l':
+		u := unicode.ToUpper(c)
+		return unicode.IsLower(c) && u != c && lowerRune(u) == c && utf8.RuneLen(u) == class.upperSize
+	case 'u':
+		return unicode.IsUpper(c) && lowerRune(c) == c
+	}
+	return unicode.IsLetter(c) && !unicode.IsLower(c) && !unicode.IsUpper(c)
+}
+
+// digitZero returns the zero of the decimal digit r. Unicode keeps the ten
+// digits of each set together and in order.
+func digitZero(r rune) rune {
+	for _, r16 := range unicode.Nd.R16 {
+		if r >= rune(r16.Lo) && r <= rune(r16.Hi) {
+			return r - (r-rune(r16.Lo))%10
+		}
+	}
+	for _, r32 := range unicode.Nd.R32 {
+		if r >= rune(r32.Lo) && r <= rune(r32.Hi) {
+			return r - (r-rune(r32.Lo))%10
+		}
+	}
+	return r
+}
+
+// coordinate returns the latitude or longitude v moved by up to a degree,
+// keeping its hemisphere or sign, the number of its digits and its being
+// no more than limit. Values that are not numbers are treated as text.
+func (a *anonymizer) coordinate(v []byte, limit float64) []byte {
+	m := coordinatePattern.FindSubmatch(v)
+	if m == nil {
+		return a.text(v)
+	}
+	whole, fraction := m[2], m[3]
+	x, err := strconv.ParseFloat(string(whole)+"."+string(fraction)+"0", 64)
+	if err != nil || x > limit {
+		return a.text(v)
+	}
+
+	unit := math.Pow10(-len(fraction))
+	lo, hi := 0.0, math.Min(limit, math.Pow10(len(whole))-unit)
+	if len(whole) > 1 && whole[0] != '0' {
+		lo = math.Pow10(len(whole) - 1)
+	}
+	x = math.Round((x+2*a.rng.Float64()-1)/unit) * unit
+	x = math.Max(lo, math.Min(hi, x))
+
+	width := len(whole)
+	if len(fraction) > 0 {
+		width += 1 + len(fraction)
+	}
+	out := append([]byte(nil), m[1]...)
+	out = append(out, fmt.Sprintf("%0*.*f", width, len(fraction), x)...)
+	return append(out, m[4]...)
+}
+
+// date returns the date value v moved by the shift, with any phrases in
+// parentheses treated as text.
+func (a *anonymizer) date(v []byte) []byte {
+	var out []byte
+	for len(v) > 0 {
+		open := bytes.IndexByte(v, '(')
+		if open < 0 {
+			open = len(v)
+		}
+		out = append(out, a.shiftDates(v[:open])...)
+		v = v[open:]
+		if len(v) == 0 {
+			break
+		}
+		end := bytes.IndexByte(v, ')')
+		if end < 0 {
+			end = len(v) - 1
+		}
+		out = append(out, a.text(v[:end+1])...)
+		v = v[end+1:]
+	}
+	return out
+}
+
+// shiftDates moves the dates in the part of a date value outside phrases.
+// Hebrew and French republican dates are moved by whole years only.
+func (a *anonymizer) shiftDates(v []byte) []byte {
+	return anonymizeDatePattern.ReplaceAllFunc(v, a.shiftDate)
+}
+
+// shiftDate moves a single date matched by anonymizeDatePattern, keeping the
+// widths of its numbers and the case of its month.
+func (a *anonymizer) shiftDate(m []byte) []byte {
+	g := anonymizeDatePattern.FindSubmatch(m)
+	day, month, year, dual := string(g[1]), string(g[2]), string(g[3]), string(g[4])
+	y, _ := strconv.Atoi(year)
+
+	var d time.Time
+	month = strings.ToUpper(month)
+	mon, gregorian := months[month]
+	switch {
+	case month != "" && !gregorian:
+		d = time.Date(y+roundDiv(a.shift, 365.25), time.January, 1, 0, 0, 0, 0, time.UTC)
+	case day != "" && gregorian:
+		dd, _ := strconv.Atoi(day)
+		d = time.Date(y, mon, dd, 0, 0, 0, 0, time.UTC).AddDate(0, 0, a.shift)
+		day = fmt.Sprintf("%0*d", len(day), d.Day())
+		month = monthNames[d.Month()]
+	case gregorian:
+		d = time.Date(y, mon, 1, 0, 0, 0, 0, time.UTC).AddDate(0, roundDiv(a.shift, 30.4375), 0)
+		month = monthNames[d.Month()]
+	default:
+		d = time.Date(y+roundDiv(a.shift, 365.25), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if d.Year() < 1 || d.Year() > 9999 {
+		return m
+	}
+	if g[2] != nil && bytes.Equal(g[2], bytes.ToLower(g[2])) {
+		month = strings.ToLower(month)
+	}
+
+	var out []string
+	if day != "" {
+		out = append(out, day)
+	}
+	if month != "" {
+		out = append(out, month)
+	}
+	year = fmt.Sprintf("%0*d", len(year), d.Year())
+	if dual != "" {
+		year += "/" + fmt.Sprintf("%0*d", len(dual), (d.Year()+1)%int(math.Pow10(len(dual))))
+	}
+	out = append(out, year)
+
+	// Keep the original spacing between the parts of the date
+	seps := dateSeparator.FindAll(m, -1)
+	var b []byte
+	for i, part := range out {
+		if i > 0 {
+			b = append(b, seps[i-1]...)
+		}
+		b = append(b, part...)
+	}
+	return b
+}
+
+// roundDiv returns days divided by unit rounded to the nearest whole number.
+func roundDiv(days int, unit float64) int {
+	return int(math.Round(float64(days) / unit))
+}
+
+// scanLinesWithEndings is a bufio.SplitFunc that splits lines ended by any of
+// CR, LF or CR LF, keeping the endings.
+func scanLinesWithEndings(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\r' {
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i+2], nil
+				}
+				return i + 1, data[:i+1], nil
+			}
+			if !atEOF {
+				return 0, nil, nil
+			}
+		}
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// isByteOrderMark reports whether c may be part of a byte order mark.
+func isByteOrderMark(c byte) bool {
+	return c == 0xEF || c == 0xBB || c == 0xBF || c == 0xFE || c == 0xFF || c == 0x00
+}
+
+// wordRune returns the length of the character at the start of v, and
+// whether it is part of a word to be replaced. Bytes that are not UTF-8 are
+// kept as they are but join the letters around them, so that a word such as
+// Müller in ANSEL or Latin-1 gets a single pseudonym. Combining marks are
+// kept too.
+func wordRune(v []byte) (int, bool) {
+	if c := v[0]; c < utf8.RuneSelf {
+		return 1, c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	}
+	r, n := utf8.DecodeRune(v)
+	if r == utf8.RuneError && n == 1 {
+		return 1, true
+	}
+	return n, unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// isPointerValue reports whether v is a pointer such as @I1@.
+func isPointerValue(v []byte) bool {
+	if len(v) < 3 || v[0] != '@' || v[len(v)-1] != '@' || v[1] == '#' {
+		return false
+	}
+	for _, c := range v[1 : len(v)-1] {
+		if c == '@' || isSpace(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny reports whether any of tags is in path.
+func containsAny(path []string, tags ...string) bool {
+	for _, p := range path {
+		for _, t := range tags {
+			if p == t {
+				return true
+			}
+		}
+	}
+	return false
+}
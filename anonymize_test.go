@@ -0,0 +1,126 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func anonymize(t *testing.T, data []byte, opts *AnonymizeOptions) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Anonymize(bytes.NewReader(data), &out, opts); err != nil {
+		t.Fatalf("Anonymize gave error %v, expected no error", err)
+	}
+	return out.Bytes()
+}
+
+func TestAnonymize(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/ours.ged")
+	if err != nil {
+		t.Fatalf("Unable to read testdata/ours.ged: %v", err)
+	}
+	out := anonymize(t, data, &AnonymizeOptions{Seed: 1, DateShift: 400})
+
+	g := decodeFile(t, "testdata/ours.ged")
+	ag, err := NewDecoder(bytes.NewReader(out)).Decode()
+	if err != nil {
+		t.Fatalf("Decode of anonymized file gave error %v, expected no error", err)
+	}
+
+	william, john := person(t, ag, "I1"), person(t, ag, "I3")
+	stringTestCases{
+		{"Individuals", xrefs(g.Individual), xrefs(ag.Individual)},
+		{"I1 birth date", "16 APR 1801", william.Event[0].Date},
+		{"I1 death date", "1871", william.Event[1].Date},
+		{"I1 sex", "M", william.Sex},
+		{"Shared surname", william.Name[0].Surname(), john.Name[0].Surname()},
+		{"Header version", "5.5.1", ag.Header.Info.Version},
+		{"F1 children", "I3", xrefs(family(t, ag, "F1").Children())},
+	}.run(t)
+
+	boolTestCases{
+		{"I1 name replaced", false, strings.Contains(william.Name[0].Name, "Grant")},
+		{"I1 name keeps its shape", true, len(william.Name[0].Name) == len("William /Grant/") && strings.Count(william.Name[0].Name, "/") == 2},
+		{"Note replaced", false, strings.Contains(string(out), "bible")},
+		{"Same seed gives the same file", true, bytes.Equal(out, anonymize(t, data, &AnonymizeOptions{Seed: 1, DateShift: 400}))},
+	}.run(t)
+
+	intTestCases{
+		{"Line count was [%d]", bytes.Count(data, []byte("\n")), bytes.Count(out, []byte("\n"))},
+		{"Length was [%d]", len(data), len(out)},
+	}.run(t)
+}
+
+func TestAnonymizeKeepsQuirks(t *testing.T) {
+	data := []byte("\xEF\xBB\xBF0 HEAD\r\n1 CHAR ANSEL\r\n0 @I1@ INDI\r\n1 NAME Jos\xE9 /M\xFCller/\r" +
+		"1 BIRT Y\r  2 DATE BET 1750/51 AND 3 jan 1760\r1 NOTE See (Jos\xE9) @I1@\n0 TRLR")
+	out := anonymize(t, data, &AnonymizeOptions{Seed: 2, DateShift: -40})
+	lines := strings.SplitAfter(strings.Replace(string(out), "\r", "\r|", -1), "|")
+
+	stringTestCases{
+		{"Byte order mark", "\xEF\xBB\xBF0 HEAD\r\n", string(out[:len("\xEF\xBB\xBF0 HEAD\r\n")])},
+		{"Character set", "1 CHAR ANSEL\r\n", strings.Split(string(out), "\r\n")[1] + "\r\n"},
+		{"Event value", "1 BIRT Y\r|", lines[4]},
+		{"Date", "  2 DATE BET 1750/51 AND 24 nov 1759\r|", lines[5]},
+		{"Trailer", "\n0 TRLR", string(out[len(out)-len("\n0 TRLR"):])},
+	}.run(t)
+
+	boolTestCases{
+		{"Non-ASCII bytes kept", true, bytes.Contains(out, []byte("\xE9")) && bytes.Contains(out, []byte("\xFC"))},
+		{"Name replaced", false, bytes.Contains(out, []byte("Jos\xE9"))},
+		{"Pointer kept", true, bytes.Contains(out, []byte(") @I1@\n"))},
+	}.run(t)
+}
+
+func TestAnonymizeUnicode(t *testing.T) {
+	data := []byte("0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME Иван /Петров/\n0 @I2@ INDI\n1 NAME José /Müller/\n" +
+		"1 NOTE José Müller – Ελένη\n1 BIRT\n2 PLAC Salem\n3 MAP\n4 LATI N42.5195\n4 LONG W070.8967\n0 TRLR\n")
+	out := anonymize(t, data, &AnonymizeOptions{Seed: 3})
+	ag, err := NewDecoder(bytes.NewReader(out)).Decode()
+	if err != nil {
+		t.Fatalf("Decode of anonymized file gave error %v, expected no error", err)
+	}
+
+	ivan, jose := person(t, ag, "I1").Name[0].Name, person(t, ag, "I2").Name[0].Name
+	note := strings.Fields(person(t, ag, "I2").Note[0].Note)
+	place := person(t, ag, "I2").Event[0].Place
+	cyrillic := true
+	for _, r := range strings.Replace(ivan, " ", "", -1) {
+		cyrillic = cyrillic && (r == '/' || unicode.Is(unicode.Cyrillic, r))
+	}
+	coordinate := func(s string) float64 {
+		x, _ := strconv.ParseFloat(s[1:], 64)
+		return x
+	}
+
+	boolTestCases{
+		{"Valid UTF-8", true, utf8.Valid(out)},
+		{"Cyrillic name replaced", false, strings.Contains(ivan, "Иван") || strings.Contains(ivan, "Петров")},
+		{"Cyrillic name keeps its script", true, cyrillic},
+		{"Cyrillic name keeps its case", true, strings.Contains(ivan, " /") && unicode.IsUpper([]rune(ivan)[0]) && unicode.IsLower([]rune(ivan)[1])},
+		{"Latin name replaced", false, strings.Contains(string(out), "José") || strings.Contains(string(out), "Müller")},
+		{"Latin name keeps its shape", true, strings.Count(jose, "/") == 2 && len(jose) == len("José /Müller/")},
+		{"Same word, same pseudonym", true, strings.HasPrefix(jose, note[0]+" /"+note[1]+"/")},
+		{"Punctuation kept", true, note[2] == "–"},
+		{"Greek word keeps its script", true, note[3] != "Ελένη" && unicode.Is(unicode.Greek, []rune(note[3])[0])},
+		{"Latitude format", true, regexp.MustCompile(`^N\d\d\.\d{4}$`).MatchString(place.Latitude)},
+		{"Longitude format", true, regexp.MustCompile(`^W0\d\d\.\d{4}$`).MatchString(place.Longitude)},
+		{"Latitude moved by up to a degree", true, place.Latitude != "N42.5195" && math.Abs(coordinate(place.Latitude)-42.5195) <= 1},
+		{"Longitude moved by up to a degree", true, place.Longitude != "W070.8967" && math.Abs(coordinate(place.Longitude)-70.8967) <= 1},
+	}.run(t)
+
+	intTestCases{
+		{"Length was [%d]", len(data), len(out)},
+	}.run(t)
+}
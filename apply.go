@@ -0,0 +1,517 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Conflict is a change that could not be applied and why.
+type Conflict struct {
+	Change *Change
+	Reason string
+}
+
+// ConflictError lists the changes that could not be applied to a file,
+// usually because it has changed since they were made.
+type ConflictError []*Conflict
+
+func (e ConflictError) Error() string {
+	msgs := make([]string, len(e))
+	for i, c := range e {
+		msgs[i] = fmt.Sprintf("%s: %s", c.Change, c.Reason)
+	}
+	return "conflicting changes: " + strings.Join(msgs, "; ")
+}
+
+// ReadPatch decodes a change set written by ChangeSet.Patch.
+func ReadPatch(r io.Reader) (ChangeSet, error) {
+	var cs ChangeSet
+	if err := json.NewDecoder(r).Decode(&cs); err != nil {
+		return ChangeSet{}, err
+	}
+	return cs, nil
+}
+
+// Apply makes the changes in cs to g. Each change must find g as it was when
+// the change was made: a field to be replaced or removed must have the old
+// value of the change, and a field or record to be added must not already
+// exist. If any change conflicts, g is left unchanged and a ConflictError
+// lists every conflict. Records that are added are created before any other
+// change is made, so that changes may refer to them, and fields are removed
+// after every other change, from the last of each tag back, so that removing
+// one does not renumber the others. The change date of every record changed
+// or added is set to now.
+func Apply(g *Gedcom, cs ChangeSet) error {
+	a := &applier{g: g, log: newChangeLog(g), touched: make(map[interface{}]bool)}
+
+	var added, changed, removed []*Change
+	for _, c := range cs.Changes {
+		switch {
+		case c.Op == ChangeAdd && c.Path == "":
+			added = append(added, c)
+		case c.Op == ChangeRemove && c.Path != "":
+			removed = append(removed, c)
+		default:
+			changed = append(changed, c)
+		}
+	}
+	sort.SliceStable(removed, func(i, j int) bool { return removesFirst(removed[i], removed[j]) })
+
+	var conflicts ConflictError
+	for _, changes := range [][]*Change{added, changed, removed} {
+		for _, c := range changes {
+			if err := a.apply(c); err != nil {
+				conflicts = append(conflicts, &Conflict{Change: c, Reason: err.Error()})
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		a.log.Undo()
+		return conflicts
+	}
+
+	for _, rec := range a.order {
+		a.log.stamp(rec)
+	}
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return nil
+}
+
+// removesFirst reports whether the removal a must be made before b. Removals
+// are grouped by record and made from the last field with a tag back to the
+// first, and from the innermost field out.
+func removesFirst(a *Change, b *Change) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Record != b.Record {
+		return a.Record < b.Record
+	}
+	as, bs := strings.Fields(a.Path), strings.Fields(b.Path)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		at, an := parseSegment(as[i])
+		bt, bn := parseSegment(bs[i])
+		if at != bt {
+			return at < bt
+		}
+		if an != bn {
+			return an > bn
+		}
+	}
+	return len(as) > len(bs)
+}
+
+// applier makes changes to a Gedcom, logging them so that they can be undone.
+type applier struct {
+	g       *Gedcom
+	log     *ChangeLog
+	touched map[interface{}]bool
+	order   []interface{} // the records touched, in order
+}
+
+// attributeTags are the tags of individual attributes, as opposed to events.
+var attributeTags = map[string]bool{
+	"CAST": true, "DSCR": true, "EDUC": true, "IDNO": true, "NATI": true,
+	"NCHI": true, "NMR": true, "OCCU": true, "PROP": true, "RELI": true,
+	"RESI": true, "SSN": true, "TITL": true, "FACT": true, "_MILT": true,
+}
+
+func (a *applier) apply(c *Change) error {
+	list, err := a.list(c.Type)
+	if err != nil {
+		return err
+	}
+	rec, index := findRecord(list, c.Record)
+	if c.Path == "" {
+		return a.applyRecord(list, rec, index, c)
+	}
+	if !rec.IsValid() {
+		return fmt.Errorf("%s %s not found", c.Type, c.Record)
+	}
+	a.touch(rec.Interface())
+
+	segments := strings.Fields(c.Path)
+	parent := rec.Elem()
+	for i, seg := range segments[:len(segments)-1] {
+		slot, found := locate(parent, seg)
+		if !found {
+			slot, found = containerField(parent, seg)
+		}
+		if !found || recordXref(slot.get()) != "" || reflect.Indirect(slot.get()).Kind() != reflect.Struct {
+			return fmt.Errorf("%s not found", strings.Join(segments[:i+1], " "))
+		}
+		parent = reflect.Indirect(slot.get())
+	}
+
+	last := segments[len(segments)-1]
+	slot, found := locate(parent, last)
+	if c.Op == ChangeAdd {
+		if found {
+			return fmt.Errorf("already present with value %q", itemValue(slot.get()))
+		}
+		return a.add(parent, last, c.New)
+	}
+	if !found {
+		return fmt.Errorf("not found")
+	}
+	if v := itemValue(slot.get()); v != c.Old {
+		return fmt.Errorf("found %q", v)
+	}
+	switch c.Op {
+	case ChangeRemove:
+		a.remove(slot)
+		return nil
+	case ChangeReplace:
+		return a.replace(slot, c.New)
+	}
+	return fmt.Errorf("unknown operation %q", c.Op)
+}
+
+// applyRecord adds, removes or replaces the text of the record rec, found at
+// index in list.
+func (a *applier) applyRecord(list reflect.Value, rec reflect.Value, index int, c *Change) error {
+	switch c.Op {
+	case ChangeAdd:
+		if rec.IsValid() {
+			return fmt.Errorf("%s %s already exists", c.Type, c.Record)
+		}
+		rec = reflect.New(list.Type().Elem().Elem())
+		setXref(rec, c.Record)
+		if err := a.setValue(rec.Elem(), c.New); err != nil {
+			return err
+		}
+		a.log.set(list, reflect.Append(list, rec))
+		a.touch(rec.Interface())
+		return nil
+	case ChangeRemove, ChangeReplace:
+		if !rec.IsValid() {
+			return fmt.Errorf("%s %s not found", c.Type, c.Record)
+		}
+		if v := recordValue(rec); v != c.Old {
+			return fmt.Errorf("found %q", v)
+		}
+		if c.Op == ChangeRemove {
+			a.remove(applySlot{reflect.ValueOf(a.g).Elem(), list, index})
+			return nil
+		}
+		a.touch(rec.Interface())
+		return a.setValue(rec.Elem(), c.New)
+	}
+	return fmt.Errorf("unknown operation %q", c.Op)
+}
+
+// list returns the list of records of g with the given tag.
+func (a *applier) list(kind string) (reflect.Value, error) {
+	for _, k := range diffKinds {
+		if k.tag == kind {
+			return reflect.ValueOf(a.g).Elem().FieldByName(k.field), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown record type %q", kind)
+}
+
+func (a *applier) touch(rec interface{}) {
+	if !a.touched[rec] {
+		a.touched[rec] = true
+		a.order = append(a.order, rec)
+	}
+}
+
+// findRecord returns the record in list with xref and its index.
+func findRecord(list reflect.Value, xref string) (reflect.Value, int) {
+	for i := 0; i < list.Len(); i++ {
+		if recordXref(list.Index(i)) == xref {
+			return list.Index(i), i
+		}
+	}
+	return reflect.Value{}, -1
+}
+
+// applySlot locates a field of a structure, or an element of a list field.
+type applySlot struct {
+	parent reflect.Value // the structure
+	field  reflect.Value
+	index  int // -1 if field is not a list
+}
+
+func (s applySlot) get() reflect.Value {
+	if s.index < 0 {
+		return s.field
+	}
+	return s.field.Index(s.index)
+}
+
+// locate finds the field or element of the structure s at the path segment
+// seg, as numbered by Diff.
+func locate(s reflect.Value, seg string) (applySlot, bool) {
+	tag, n := parseSegment(seg)
+	count := 0
+	for _, f := range diffStructures[s.Type()].fields {
+		if f.tag != "" && f.tag != tag {
+			continue
+		}
+		v := s.FieldByName(f.name)
+		if v.Kind() != reflect.Slice {
+			if f.tag == "" && reflect.Indirect(v).FieldByName("Tag").String() != tag {
+				continue
+			}
+			count++
+			if count == n && len(flattenValue(v, "", false, nil, nil)) > 0 {
+				return applySlot{s, v, -1}, true
+			}
+			continue
+		}
+		for i := 0; i < v.Len(); i++ {
+			if f.tag == "" && reflect.Indirect(v.Index(i)).FieldByName("Tag").String() != tag {
+				continue
+			}
+			count++
+			if count == n {
+				return applySlot{s, v, i}, true
+			}
+		}
+	}
+	return applySlot{}, false
+}
+
+// containerField finds the field of the structure s at the path segment seg
+// if it holds a structure or a pointer to one, even when empty, so that the
+// fields of a structure just added can be added in turn.
+func containerField(s reflect.Value, seg string) (applySlot, bool) {
+	tag, n := parseSegment(seg)
+	for _, f := range diffStructures[s.Type()].fields {
+		if f.tag == tag && n == 1 {
+			v := s.FieldByName(f.name)
+			if v.Kind() == reflect.Struct || v.Kind() == reflect.Ptr && !v.IsNil() {
+				return applySlot{s, v, -1}, true
+			}
+		}
+	}
+	return applySlot{}, false
+}
+
+// parseSegment splits a path segment such as RESI[2] into its tag and number.
+func parseSegment(seg string) (string, int) {
+	if i := strings.Index(seg, "["); i > 0 && strings.HasSuffix(seg, "]") {
+		if n, err := strconv.Atoi(seg[i+1 : len(seg)-1]); err == nil {
+			return seg[:i], n
+		}
+	}
+	return seg, 1
+}
+
+// itemValue returns the value of a field or element as Diff gives it.
+func itemValue(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String()
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		return ""
+	case v.Kind() == reflect.Ptr && recordXref(v) != "":
+		return pointerValue(v, nil)
+	}
+	v = reflect.Indirect(v)
+	if name := diffStructures[v.Type()].value; name != "" {
+		return scalarValue(v.FieldByName(name), nil)
+	}
+	return ""
+}
+
+// add adds the field of the structure s with the path segment seg, which
+// must follow any others with the same tag.
+func (a *applier) add(s reflect.Value, seg string, value string) error {
+	tag, n := parseSegment(seg)
+	if n > 1 {
+		if _, found := locate(s, pathSegment(tag, n-1)); !found {
+			return fmt.Errorf("%s not found", pathSegment(tag, n-1))
+		}
+	}
+
+	f, ok := addField(s.Type(), tag)
+	if !ok {
+		return fmt.Errorf("unknown tag %s", tag)
+	}
+	v := s.FieldByName(f.name)
+	if v.Kind() != reflect.Slice && n > 1 {
+		return fmt.Errorf("%s may only occur once", tag)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		a.setString(s, v, value)
+		return nil
+	case reflect.Struct:
+		return a.setValue(v, value)
+	case reflect.Ptr:
+		e, err := a.newItem(v.Type(), tag, value)
+		if err != nil {
+			return err
+		}
+		a.log.set(v, e)
+		return nil
+	case reflect.Slice:
+		e, err := a.newItem(v.Type().Elem(), tag, value)
+		if err != nil {
+			return err
+		}
+		l := reflect.MakeSlice(v.Type(), 0, v.Len()+1)
+		a.log.set(v, reflect.Append(reflect.AppendSlice(l, v), e))
+		return nil
+	}
+	return fmt.Errorf("cannot add %s", tag)
+}
+
+// addField returns the field of a structure of type t to which a field with
+// tag is added.
+func addField(t reflect.Type, tag string) (diffField, bool) {
+	var byElement []diffField
+	for _, f := range diffStructures[t].fields {
+		if f.tag == tag {
+			return f, true
+		}
+		if f.tag == "" {
+			byElement = append(byElement, f)
+		}
+	}
+	switch {
+	case len(byElement) == 0:
+		return diffField{}, false
+	case t == reflect.TypeOf(IndividualRecord{}) && attributeTags[tag]:
+		for _, f := range byElement {
+			if f.name == "Attribute" {
+				return f, true
+			}
+		}
+	}
+	return byElement[0], true
+}
+
+// newItem returns a new value of type t, a string or pointer, for a field
+// with tag and value. Pointers to records are found in g.
+func (a *applier) newItem(t reflect.Type, tag string, value string) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(value).Convert(t), nil
+	}
+	if isPointer(t, value) {
+		return a.resolve(t, value)
+	}
+	e := reflect.New(t.Elem())
+	if f := e.Elem().FieldByName("Tag"); f.IsValid() && f.Kind() == reflect.String {
+		f.SetString(tag)
+	}
+	if err := a.setValue(e.Elem(), value); err != nil {
+		return reflect.Value{}, err
+	}
+	return e, nil
+}
+
+// replace replaces the value of the field or element at slot.
+func (a *applier) replace(slot applySlot, value string) error {
+	v := slot.get()
+	switch {
+	case v.Kind() == reflect.String:
+		if slot.index >= 0 {
+			return a.setElement(slot, reflect.ValueOf(value))
+		}
+		a.setString(slot.parent, v, value)
+		return nil
+	case v.Kind() == reflect.Ptr && (recordXref(v) != "" || isPointer(v.Type(), value)):
+		// A pointer to a record replaced by inline text, as a NOTE may be,
+		// or the other way round
+		r, err := a.newItem(v.Type(), "", value)
+		if err != nil {
+			return err
+		}
+		if slot.index >= 0 {
+			return a.setElement(slot, r)
+		}
+		a.log.set(v, r)
+		return nil
+	}
+	return a.setValue(reflect.Indirect(v), value)
+}
+
+// setElement replaces the element of a list at slot.
+func (a *applier) setElement(slot applySlot, e reflect.Value) error {
+	l := reflect.MakeSlice(slot.field.Type(), slot.field.Len(), slot.field.Len())
+	reflect.Copy(l, slot.field)
+	l.Index(slot.index).Set(e)
+	a.log.set(slot.field, l)
+	return nil
+}
+
+// remove removes the field or element at slot.
+func (a *applier) remove(slot applySlot) {
+	if slot.index < 0 {
+		a.log.set(slot.field, reflect.Zero(slot.field.Type()))
+		return
+	}
+	l := reflect.MakeSlice(slot.field.Type(), 0, slot.field.Len()-1)
+	l = reflect.AppendSlice(l, slot.field.Slice(0, slot.index))
+	l = reflect.AppendSlice(l, slot.field.Slice(slot.index+1, slot.field.Len()))
+	a.log.set(slot.field, l)
+}
+
+// setValue sets the value of the structure s, which must have one unless
+// value is empty.
+func (a *applier) setValue(s reflect.Value, value string) error {
+	name := diffStructures[s.Type()].value
+	if name == "" {
+		if value != "" {
+			return fmt.Errorf("%s takes no value", strings.TrimSuffix(s.Type().Name(), "Record"))
+		}
+		return nil
+	}
+	f := s.FieldByName(name)
+	if f.Kind() != reflect.Ptr {
+		a.setString(s, f, value)
+		return nil
+	}
+	if value == "" {
+		a.log.set(f, reflect.Zero(f.Type()))
+		return nil
+	}
+	r, err := a.resolve(f.Type(), value)
+	if err != nil {
+		return err
+	}
+	a.log.set(f, r)
+	return nil
+}
+
+// setString sets the string field f of the structure s, keeping the sort
+// date of an event in step with its date.
+func (a *applier) setString(s reflect.Value, f reflect.Value, value string) {
+	a.log.set(f, reflect.ValueOf(value))
+	if e, ok := s.Addr().Interface().(*EventRecord); ok && f.Addr().Interface() == &e.Date {
+		a.log.set(s.FieldByName("SortDate"), reflect.ValueOf(getSortDate(value)))
+	}
+}
+
+// resolve returns the record of type t, a pointer to a record, with the xref
+// in the pointer value such as @I1@.
+func (a *applier) resolve(t reflect.Type, value string) (reflect.Value, error) {
+	xref := stripXref(value)
+	for _, rec := range a.g.xrefRecords() {
+		if rec.Type() == t && recordXref(rec) == xref {
+			return rec, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%s not found", value)
+}
+
+// isPointer reports whether value is a pointer to a record of type t.
+func isPointer(t reflect.Type, value string) bool {
+	return hasXref(t) && strings.HasPrefix(value, "@") && stripXref(value) != value
+}
@@ -0,0 +1,95 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC) }
+
+	base, revised := decodeFile(t, "testdata/original.ged"), decodeFile(t, "testdata/revised.ged")
+	patch, err := Diff(base, revised).Patch()
+	if err != nil {
+		t.Fatalf("Patch gave error %v, expected no error", err)
+	}
+	cs, err := ReadPatch(bytes.NewReader(patch))
+	if err != nil {
+		t.Fatalf("ReadPatch gave error %v, expected no error", err)
+	}
+
+	if err := Apply(base, cs); err != nil {
+		t.Fatalf("Apply gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Changes remaining after apply was [%d]", 0, len(Diff(base, revised).Changes)},
+		{"Individual count after apply was [%d]", 4, len(base.Individual)},
+		{"Repository count after apply was [%d]", 0, len(base.Repository)},
+		{"I1 birth year after apply was [%d]", 1801, person(t, base, "I1").Event[0].SortDate.Year()},
+	}.run(t)
+
+	stringTestCases{
+		{"I1 change date", "5 MAR 2024", person(t, base, "I1").Changed.Stamp.Date},
+		{"I1 change time", "14:30:00", person(t, base, "I1").Changed.Stamp.Time},
+		{"I4 change date", "5 MAR 2024", person(t, base, "I4").Changed.Stamp.Date},
+		{"I4 parents", "F1", person(t, base, "I4").Parents[0].Family.Xref},
+		{"F1 children", "I3 I4", xrefs(family(t, base, "F1").Children())},
+		{"Birth place", "Kirkby", person(t, base, "I1").Event[0].Place.Place.Name},
+	}.run(t)
+
+	boolTestCases{
+		{"I2 change date set", false, person(t, base, "I2").Changed != nil},
+	}.run(t)
+}
+
+func TestApplyConflict(t *testing.T) {
+	base, revised := decodeFile(t, "testdata/original.ged"), decodeFile(t, "testdata/revised.ged")
+	cs := Diff(base, revised)
+
+	person(t, base, "I1").Event[0].Date = "13 MAR 1800"
+	before := decodeFile(t, "testdata/original.ged")
+	person(t, before, "I1").Event[0].Date = "13 MAR 1800"
+
+	err := Apply(base, cs)
+	conflicts, ok := err.(ConflictError)
+	if !ok {
+		t.Fatalf("Apply gave error %v, expected a ConflictError", err)
+	}
+
+	intTestCases{
+		{"Conflict count was [%d]", 1, len(conflicts)},
+		{"Changes after failed apply was [%d]", 0, len(Diff(before, base).Changes)},
+		{"Individual count after failed apply was [%d]", 3, len(base.Individual)},
+	}.run(t)
+
+	stringTestCases{
+		{"Conflict", `I1 BIRT DATE changed from 12 MAR 1800 to ABT 1801: found "13 MAR 1800"`, conflicts[0].Change.String() + ": " + conflicts[0].Reason},
+	}.run(t)
+}
+
+func TestApplyRoundTrip(t *testing.T) {
+	files := []string{
+		"original.ged", "revised.ged", "ours.ged", "cousin.ged", "merge.ged", "duplicates.ged",
+		"pedigree.ged", "places.ged", "names.ged", "living.ged", "renumber.ged", "notes.ged",
+		"nested.ged", "allged.ged", "events.ged", "kennedy.ged", "media.ged",
+	}
+	for _, from := range files {
+		for _, to := range files {
+			base, target := decodeFile(t, "testdata/"+from), decodeFile(t, "testdata/"+to)
+			if err := Apply(base, Diff(base, target)); err != nil {
+				t.Errorf("Apply of %s to %s gave error %v, expected no error", to, from, err)
+				continue
+			}
+			if cs := Diff(base, target); len(cs.Changes) > 0 {
+				t.Errorf("Apply of %s to %s left changes:\n%s", to, from, cs)
+			}
+		}
+	}
+}
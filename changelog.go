@@ -0,0 +1,88 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ChangeLog records the changes made to a Gedcom by an edit such as a merge,
+// so that they can be reviewed and undone.
+type ChangeLog struct {
+	Changes []string // descriptions of the changes, in the order made
+	undo    []func()
+	g       *Gedcom
+}
+
+func newChangeLog(g *Gedcom) *ChangeLog {
+	return &ChangeLog{g: g}
+}
+
+// Undo reverts every change in the log, latest first, and empties the log.
+func (l *ChangeLog) Undo() {
+	for i := len(l.undo) - 1; i >= 0; i-- {
+		l.undo[i]()
+	}
+	l.undo = nil
+	l.Changes = nil
+	if l.g != nil {
+		l.g.Place = indexPlaces(l.g)
+		l.g.Reindex()
+	}
+}
+
+// note adds a description of a change to the log.
+func (l *ChangeLog) note(format string, args ...interface{}) {
+	l.Changes = append(l.Changes, fmt.Sprintf(format, args...))
+}
+
+// save records the current contents of the struct pointed to by rec so that
+// Undo restores them. Slices held by rec must afterwards be replaced rather
+// than modified in place.
+func (l *ChangeLog) save(rec interface{}) {
+	v := reflect.ValueOf(rec).Elem()
+	old := reflect.New(v.Type()).Elem()
+	old.Set(v)
+	l.undo = append(l.undo, func() { v.Set(old) })
+}
+
+// set assigns x to the settable value v, recording the old value.
+func (l *ChangeLog) set(v reflect.Value, x reflect.Value) {
+	old := reflect.New(v.Type()).Elem()
+	old.Set(v)
+	v.Set(x)
+	l.undo = append(l.undo, func() { v.Set(old) })
+}
+
+// timeNow returns the time used for change stamps.
+var timeNow = time.Now
+
+// stamp sets the change date of the record rec, if it has one, to now,
+// keeping any notes on the change.
+func (l *ChangeLog) stamp(rec interface{}) {
+	v := reflect.ValueOf(rec).Elem().FieldByName("Changed")
+	if !v.IsValid() {
+		return
+	}
+	l.set(v, reflect.ValueOf(newChangedRecord(v.Interface().(*ChangedRecord))))
+}
+
+// newChangedRecord returns a change record dated now with the notes of old,
+// if not nil.
+func newChangedRecord(old *ChangedRecord) *ChangedRecord {
+	now := timeNow()
+	c := &ChangedRecord{Stamp: &TimestampRecord{
+		Date: strings.ToUpper(now.Format("2 Jan 2006")),
+		Time: now.Format("15:04:05"),
+	}}
+	if old != nil {
+		c.Note = old.Note
+	}
+	return c
+}
@@ -0,0 +1,164 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+)
+
+// ConnectionOptions controls which links FindConnection may follow. Blood
+// links between parents and children are always followed.
+type ConnectionOptions struct {
+	NoMarriage   bool // do not follow links between spouses
+	NoAdoption   bool // do not follow adoptive and other non-birth parent links
+	Associations bool // follow ASSO links to associated people
+}
+
+// ConnectionStep is one person in a chain connecting two individuals.
+type ConnectionStep struct {
+	Person   *IndividualRecord
+	Relation string // what Person is to the previous person, such as "wife"
+}
+
+// A Connection is a chain of people linking one individual to another.
+type Connection []*ConnectionStep
+
+// FindConnection returns the shortest chain of family links from a to b, or
+// nil if they are not connected. Associations are followed only from the
+// person that records them.
+func FindConnection(a *IndividualRecord, b *IndividualRecord, opts *ConnectionOptions) Connection {
+	if opts == nil {
+		opts = &ConnectionOptions{}
+	}
+
+	prev := map[*IndividualRecord]*ConnectionStep{a: nil}
+	from := map[*ConnectionStep]*IndividualRecord{}
+	queue := []*IndividualRecord{a}
+
+	for len(queue) > 0 && prev[b] == nil && a != b {
+		p := queue[0]
+		queue = queue[1:]
+		for _, s := range neighbours(p, opts) {
+			if _, found := prev[s.Person]; found {
+				continue
+			}
+			prev[s.Person] = s
+			from[s] = p
+			queue = append(queue, s.Person)
+		}
+	}
+
+	if _, found := prev[b]; !found {
+		return nil
+	}
+
+	var c Connection
+	for p := b; p != a; {
+		s := prev[p]
+		c = append(Connection{s}, c...)
+		p = from[s]
+	}
+	return append(Connection{{Person: a}}, c...)
+}
+
+// neighbours returns the people directly linked to p and how each is related to p.
+func neighbours(p *IndividualRecord, opts *ConnectionOptions) []*ConnectionStep {
+	var steps []*ConnectionStep
+	add := func(q *IndividualRecord, relation string) {
+		if q != nil && q != p {
+			steps = append(steps, &ConnectionStep{Person: q, Relation: relation})
+		}
+	}
+
+	for _, l := range p.Parents {
+		if l.Family == nil {
+			continue
+		}
+		for n, parent := range []*IndividualRecord{l.Family.Husband, l.Family.Wife} {
+			if parent == nil {
+				continue
+			}
+			adopted := !isBirthLink(p, l.Family, n == 0)
+			if adopted && opts.NoAdoption {
+				continue
+			}
+			relation := gendered(parent.Sex, "father", "mother", "parent")
+			if adopted {
+				relation = "adoptive " + relation
+			}
+			add(parent, relation)
+		}
+	}
+
+	for _, l := range p.Family {
+		if l.Family == nil {
+			continue
+		}
+		if !opts.NoMarriage {
+			for _, s := range []*IndividualRecord{l.Family.Husband, l.Family.Wife} {
+				if s != nil {
+					add(s, gendered(s.Sex, "husband", "wife", "spouse"))
+				}
+			}
+		}
+		for _, c := range l.Family.Children() {
+			adopted := !isBirthLink(c, l.Family, l.Family.Husband == p)
+			if adopted && opts.NoAdoption {
+				continue
+			}
+			relation := gendered(c.Sex, "son", "daughter", "child")
+			if adopted {
+				relation = "adopted " + relation
+			}
+			add(c, relation)
+		}
+	}
+
+	if opts.Associations {
+		assocs := p.Association
+		for _, e := range p.Event {
+			assocs = append(assocs, e.Association...)
+		}
+		for _, a := range assocs {
+			relation := "associate"
+			if a.Relation != "" {
+				relation += " (" + strings.ToLower(a.Relation) + ")"
+			}
+			add(a.Person, relation)
+		}
+	}
+
+	return steps
+}
+
+// String describes the connection, for example
+// "John Smith → wife Mary Smith → her father Peter Jones".
+func (c Connection) String() string {
+	var parts []string
+	for i, s := range c {
+		switch i {
+		case 0:
+			parts = append(parts, s.Person.displayName())
+		case 1:
+			parts = append(parts, s.Relation+" "+s.Person.displayName())
+		default:
+			pronoun := gendered(c[i-1].Person.Sex, "his", "her", "their")
+			parts = append(parts, pronoun+" "+s.Relation+" "+s.Person.displayName())
+		}
+	}
+	return strings.Join(parts, " → ")
+}
+
+// displayName returns the individual's first name without surname slashes,
+// or the xref if the individual has no name.
+func (i *IndividualRecord) displayName() string {
+	if len(i.Name) > 0 {
+		if n := strings.Join(strings.Fields(strings.Replace(i.Name[0].Name, "/", " ", -1)), " "); n != "" {
+			return n
+		}
+	}
+	return "@" + i.Xref + "@"
+}
@@ -0,0 +1,63 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestFindConnection(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	conn := func(a, b string, opts *ConnectionOptions) Connection {
+		return FindConnection(person(t, pg, a), person(t, pg, b), opts)
+	}
+
+	stringTestCases{
+		{"Wife's father-in-law", "Jane Smith → husband John Grant → his father William Grant", conn("I5", "I1", nil).String()},
+		{"Father-in-law who is also an uncle", "James Grant → wife Sarah Hill → her father Robert Hill", conn("I7", "I6", nil).String()},
+		{"Half-brother", "John Grant → mother Mary Brown → her son Paul Brown", conn("I3", "I15", nil).String()},
+		{"Adopted sister", "Thomas Grant → father James Grant → his adopted daughter Lucy Grant", conn("I9", "I10", nil).String()},
+		{"Self", "Thomas Grant", conn("I9", "I9", nil).String()},
+	}.run(t)
+
+	intTestCases{
+		{"Connection length to half-brother was [%d]", 3, len(conn("I15", "I3", nil))},
+		{"Connection length to wife through their son was [%d]", 3, len(conn("I7", "I8", &ConnectionOptions{NoMarriage: true}))},
+		{"Connection length to co-grandparent without marriage was [%d]", 5, len(conn("I5", "I6", &ConnectionOptions{NoMarriage: true}))},
+	}.run(t)
+
+	boolTestCases{
+		{"Unconnected people", true, conn("I9", "I11", nil) == nil},
+		{"Adopted sister without adoption links", true, len(conn("I9", "I10", &ConnectionOptions{NoAdoption: true})) == 0},
+	}.run(t)
+}
+
+func TestFindConnectionChildRelation(t *testing.T) {
+	ag := decodeFile(t, "testdata/allged.ged")
+	conn := func(a, b string, opts *ConnectionOptions) Connection {
+		return FindConnection(person(t, ag, a), person(t, ag, b), opts)
+	}
+
+	stringTestCases{
+		{"Adoptive father by _FREL", "Child 3 → adoptive father given name surname", conn("PERSON7", "PERSON1", nil).String()},
+		{"Without adoption links", "Child 3 → mother 2nd Wife → her husband given name surname", conn("PERSON7", "PERSON1", &ConnectionOptions{NoAdoption: true}).String()},
+		{"Adopted child by _FREL", "given name surname → adopted child Child 3", conn("PERSON1", "PERSON7", nil).String()},
+	}.run(t)
+}
+
+func TestFindConnectionAssociations(t *testing.T) {
+
+	eg := decodeFile(t, "testdata/events.ged")
+	john, peter := eg.Individual[0], eg.Individual[1]
+
+	boolTestCases{
+		{"Associates without association links", true, FindConnection(john, peter, nil) == nil},
+	}.run(t)
+
+	stringTestCases{
+		{"Godfather", "John Smith → associate (godfather) Peter Jones", FindConnection(john, peter, &ConnectionOptions{Associations: true}).String()},
+	}.run(t)
+}
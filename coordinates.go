@@ -0,0 +1,165 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseLatitude parses a latitude such as "N42.3601" into decimal degrees,
+// negative for the southern hemisphere. Besides the GEDCOM form it accepts
+// signed decimals, a trailing hemisphere letter, decimal commas and
+// degree-minute-second forms like 42°21'36"N or "42 21 36 N".
+func ParseLatitude(s string) (float64, error) {
+	return parseCoordinate(s, 'N', 'S', 90)
+}
+
+// ParseLongitude parses a longitude such as "W71.0589" into decimal degrees,
+// negative for the western hemisphere. It accepts the same forms as
+// ParseLatitude.
+func ParseLongitude(s string) (float64, error) {
+	return parseCoordinate(s, 'E', 'W', 180)
+}
+
+// FormatLatitude returns the GEDCOM form of a latitude in decimal degrees.
+func FormatLatitude(lat float64) string {
+	return formatCoordinate(lat, 'N', 'S')
+}
+
+// FormatLongitude returns the GEDCOM form of a longitude in decimal degrees.
+func FormatLongitude(long float64) string {
+	return formatCoordinate(long, 'E', 'W')
+}
+
+// Coordinates returns the latitude and longitude of the place in decimal
+// degrees. An error is returned if either is missing or invalid.
+func (p *PlaceRecord) Coordinates() (lat float64, long float64, err error) {
+	return parseCoordinates(p.Latitude, p.Longitude)
+}
+
+// Coordinates returns the latitude and longitude of the place in decimal
+// degrees. An error is returned if either is missing or invalid.
+func (p *Place) Coordinates() (lat float64, long float64, err error) {
+	return parseCoordinates(p.Latitude, p.Longitude)
+}
+
+// SetCoordinates stores lat and long, given in decimal degrees, in GEDCOM form.
+func (p *PlaceRecord) SetCoordinates(lat float64, long float64) error {
+	if math.IsNaN(lat) || math.Abs(lat) > 90 {
+		return fmt.Errorf("latitude %v out of range", lat)
+	}
+	if math.IsNaN(long) || math.Abs(long) > 180 {
+		return fmt.Errorf("longitude %v out of range", long)
+	}
+	p.Latitude = FormatLatitude(lat)
+	p.Longitude = FormatLongitude(long)
+	return nil
+}
+
+func parseCoordinates(latitude string, longitude string) (lat float64, long float64, err error) {
+	if latitude == "" || longitude == "" {
+		return 0, 0, fmt.Errorf("place has no coordinates")
+	}
+	if lat, err = ParseLatitude(latitude); err != nil {
+		return 0, 0, err
+	}
+	if long, err = ParseLongitude(longitude); err != nil {
+		return 0, 0, err
+	}
+	return lat, long, nil
+}
+
+func parseCoordinate(s string, pos rune, neg rune, limit float64) (float64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "" {
+		return 0, fmt.Errorf("empty coordinate")
+	}
+
+	sign := 1.0
+	hemisphere := false
+	strip := func(r rune) {
+		switch r {
+		case pos:
+		case neg:
+			sign = -1
+		default:
+			return
+		}
+		hemisphere = true
+	}
+
+	if r := rune(v[0]); unicode.IsLetter(r) {
+		strip(r)
+		if !hemisphere {
+			return 0, fmt.Errorf("coordinate %q has invalid hemisphere %q", s, r)
+		}
+		v = strings.TrimSpace(v[1:])
+	} else if r := rune(v[len(v)-1]); unicode.IsLetter(r) {
+		strip(r)
+		if !hemisphere {
+			return 0, fmt.Errorf("coordinate %q has invalid hemisphere %q", s, r)
+		}
+		v = strings.TrimSpace(v[:len(v)-1])
+	}
+
+	if strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+") {
+		if hemisphere {
+			return 0, fmt.Errorf("coordinate %q has both a sign and a hemisphere", s)
+		}
+		if v[0] == '-' {
+			sign = -1
+		}
+		v = strings.TrimSpace(v[1:])
+	}
+
+	if strings.IndexFunc(v, unicode.IsLetter) >= 0 {
+		return 0, fmt.Errorf("coordinate %q is not in a recognized form", s)
+	}
+
+	// A lone comma with no point is a decimal comma
+	if strings.Count(v, ",") == 1 && !strings.Contains(v, ".") {
+		v = strings.Replace(v, ",", ".", 1)
+	}
+
+	fields := strings.FieldsFunc(v, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.'
+	})
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, fmt.Errorf("coordinate %q is not in a recognized form", s)
+	}
+
+	var parts [3]float64
+	for i, f := range fields {
+		n, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return 0, fmt.Errorf("coordinate %q is not in a recognized form", s)
+		}
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("coordinate %q has minutes or seconds out of range", s)
+		}
+		parts[i] = n
+	}
+
+	deg := parts[0] + parts[1]/60 + parts[2]/3600
+	if deg > limit {
+		return 0, fmt.Errorf("coordinate %q out of range", s)
+	}
+
+	return sign * deg, nil
+}
+
+func formatCoordinate(v float64, pos byte, neg byte) string {
+	h := pos
+	if v < 0 {
+		h = neg
+		v = -v
+	}
+	return string(h) + strconv.FormatFloat(v, 'f', -1, 64)
+}
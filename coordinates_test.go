@@ -0,0 +1,80 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+
+	var latitudeTests = []struct {
+		in      string
+		correct float64
+	}{
+		{"N42.3601", 42.3601},
+		{"S33.8688", -33.8688},
+		{"42.3601", 42.3601},
+		{"-33.8688", -33.8688},
+		{"n 42.3601", 42.3601},
+		{"42.3601N", 42.3601},
+		{"42,3601", 42.3601},
+		{`42°21'36"N`, 42.36},
+		{"42 21 36 S", -42.36},
+		{"N42:21", 42.35},
+		{"N90", 90},
+	}
+
+	for _, tt := range latitudeTests {
+		result, err := ParseLatitude(tt.in)
+		if err != nil {
+			t.Errorf("%q gave error %v, expected %v", tt.in, err, tt.correct)
+		} else if math.Abs(result-tt.correct) > 1e-9 {
+			t.Errorf("%q => %v, want %v", tt.in, result, tt.correct)
+		}
+	}
+
+	long, err := ParseLongitude("W71.0589")
+	if err != nil || long != -71.0589 {
+		t.Errorf("%q => %v (%v), want %v", "W71.0589", long, err, -71.0589)
+	}
+
+	var invalid = []string{"", "N91", "E42.1", "-N42", "N42 61", "north", "1 2 3 4"}
+	for _, in := range invalid {
+		if result, err := ParseLatitude(in); err == nil {
+			t.Errorf("%q => %v, expected error", in, result)
+		}
+	}
+
+	if result, err := ParseLongitude("W180.5"); err == nil {
+		t.Errorf("%q => %v, expected error", "W180.5", result)
+	}
+}
+
+func TestPlaceCoordinates(t *testing.T) {
+
+	p := g.Individual[0].Event[0].Place
+	lat, long, err := p.Coordinates()
+	if err != nil {
+		t.Fatalf("Coordinates gave error %v, expected no error", err)
+	}
+
+	var r PlaceRecord
+	if err := r.SetCoordinates(lat, long); err != nil {
+		t.Fatalf("SetCoordinates gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Encoded latitude", "N42.157841", r.Latitude},
+		{"Encoded longitude", "W78.715065", r.Longitude},
+		{"Encoded southern latitude", "S0.5", FormatLatitude(-0.5)},
+		{"Encoded eastern longitude", "E151.2093", FormatLongitude(151.2093)},
+	}.run(t)
+
+	if err := r.SetCoordinates(95, 0); err == nil {
+		t.Errorf("SetCoordinates with latitude 95 gave no error, expected error")
+	}
+}
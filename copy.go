@@ -0,0 +1,69 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+)
+
+// Copy returns a deep copy of g. Records referred to from several places in
+// g are referred to from the same places in the copy, so a record and the
+// references to it can be changed in the copy without affecting g.
+func (g *Gedcom) Copy() *Gedcom {
+	c := &copier{done: make(map[interface{}]reflect.Value)}
+	cg := c.copy(reflect.ValueOf(g)).Interface().(*Gedcom)
+	cg.Place = indexPlaces(cg)
+	return cg
+}
+
+// copier deep copies records, remembering the copy of each so that shared
+// records stay shared.
+type copier struct {
+	done map[interface{}]reflect.Value
+}
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if _, ok := v.Interface().(*Place); ok {
+			// the place index is rebuilt rather than copied
+			return reflect.Zero(v.Type())
+		}
+		if n, ok := c.done[v.Interface()]; ok {
+			return n
+		}
+		n := reflect.New(v.Type().Elem())
+		c.done[v.Interface()] = n
+		n.Elem().Set(c.copy(v.Elem()))
+		return n
+
+	case reflect.Struct:
+		if v.Type().PkgPath() != pkgPath {
+			return v
+		}
+		n := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if f := n.Field(i); f.CanSet() {
+				f.Set(c.copy(v.Field(i)))
+			}
+		}
+		return n
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	}
+	return v
+}
@@ -0,0 +1,166 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateRange is the span of days a GEDCOM date value may refer to. A zero
+// Earliest or Latest means the range is open at that end, as with
+// "BEF 1900" or "AFT 1900".
+type DateRange struct {
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// approximateYears is how far either side of the stated date an approximate
+// date (ABT, CAL, EST) is assumed to extend.
+const approximateYears = 2
+
+var (
+	datePattern    = regexp.MustCompile(`^(?:(\d{1,2})\s+)?(?:(JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC)\s+)?(\d{1,4})(?:/(\d{1,2}))?$`)
+	calendarEscape = regexp.MustCompile(`@#D[A-Z ]+@\s*`)
+	months         = map[string]time.Month{
+		"JAN": time.January, "FEB": time.February, "MAR": time.March, "APR": time.April,
+		"MAY": time.May, "JUN": time.June, "JUL": time.July, "AUG": time.August,
+		"SEP": time.September, "OCT": time.October, "NOV": time.November, "DEC": time.December,
+	}
+)
+
+// ParseDateRange parses a GEDCOM date value such as "ABT 1850",
+// "BET 1 JAN 1850 AND MAR 1851" or "FROM 1900 TO 1910" into the range of days
+// it covers. Date phrases and dates before the common era are not supported.
+func ParseDateRange(date string) (DateRange, error) {
+	v := strings.ToUpper(strings.TrimSpace(date))
+	v = calendarEscape.ReplaceAllString(v, "")
+	if i := strings.Index(v, "("); i >= 0 {
+		// INT dates carry a phrase after the interpreted date
+		v = strings.TrimSpace(v[:i])
+	}
+
+	word := func(prefix string) bool {
+		if strings.HasPrefix(v, prefix+" ") {
+			v = strings.TrimSpace(v[len(prefix):])
+			return true
+		}
+		return false
+	}
+
+	switch {
+	case word("BET"):
+		parts := strings.SplitN(v, " AND ", 2)
+		if len(parts) != 2 {
+			return DateRange{}, fmt.Errorf("date %q has BET without AND", date)
+		}
+		return joinDates(date, parts[0], parts[1])
+	case word("FROM"):
+		parts := strings.SplitN(v, " TO ", 2)
+		if len(parts) == 1 {
+			from, err := parseDate(parts[0])
+			return DateRange{Earliest: from.Earliest}, err
+		}
+		return joinDates(date, parts[0], parts[1])
+	case word("TO"), word("BEF"):
+		to, err := parseDate(v)
+		if err != nil {
+			return DateRange{}, err
+		}
+		return DateRange{Latest: to.Latest}, nil
+	case word("AFT"):
+		from, err := parseDate(v)
+		if err != nil {
+			return DateRange{}, err
+		}
+		return DateRange{Earliest: from.Earliest}, nil
+	case word("ABT"), word("CAL"), word("EST"):
+		r, err := parseDate(v)
+		if err != nil {
+			return DateRange{}, err
+		}
+		r.Earliest = r.Earliest.AddDate(-approximateYears, 0, 0)
+		r.Latest = r.Latest.AddDate(approximateYears, 0, 0)
+		return r, nil
+	case word("INT"):
+	}
+
+	return parseDate(v)
+}
+
+// joinDates returns the range from the start of the first date to the end of
+// the second.
+func joinDates(date string, first string, second string) (DateRange, error) {
+	from, err := parseDate(first)
+	if err != nil {
+		return DateRange{}, err
+	}
+	to, err := parseDate(second)
+	if err != nil {
+		return DateRange{}, err
+	}
+	if to.Latest.Before(from.Earliest) {
+		return DateRange{}, fmt.Errorf("date %q ends before it starts", date)
+	}
+	return DateRange{Earliest: from.Earliest, Latest: to.Latest}, nil
+}
+
+// parseDate parses a single calendar date of a year, month and year or day,
+// month and year.
+func parseDate(date string) (DateRange, error) {
+	m := datePattern.FindStringSubmatch(strings.TrimSpace(date))
+	if m == nil {
+		return DateRange{}, fmt.Errorf("date %q is not in a recognized form", date)
+	}
+
+	year, _ := strconv.Atoi(m[3])
+	lastYear := year
+	if m[4] != "" {
+		// Dual dated years such as 1750/51 could be either year
+		lastYear++
+	}
+
+	if m[2] == "" {
+		return DateRange{
+			Earliest: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Latest:   time.Date(lastYear, time.December, 31, 0, 0, 0, 0, time.UTC),
+		}, nil
+	}
+
+	month := months[m[2]]
+	if m[1] == "" {
+		return DateRange{
+			Earliest: time.Date(year, month, 1, 0, 0, 0, 0, time.UTC),
+			Latest:   time.Date(lastYear, month+1, 0, 0, 0, 0, 0, time.UTC),
+		}, nil
+	}
+
+	day, _ := strconv.Atoi(m[1])
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if d.Day() != day {
+		return DateRange{}, fmt.Errorf("date %q does not exist", date)
+	}
+	return DateRange{Earliest: d, Latest: time.Date(lastYear, month, day, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+// IsOpen reports whether either end of the range is unknown.
+func (r DateRange) IsOpen() bool {
+	return r.Earliest.IsZero() || r.Latest.IsZero()
+}
+
+// Overlaps reports whether the two ranges could refer to the same day.
+func (r DateRange) Overlaps(o DateRange) bool {
+	if !r.Latest.IsZero() && !o.Earliest.IsZero() && r.Latest.Before(o.Earliest) {
+		return false
+	}
+	if !o.Latest.IsZero() && !r.Earliest.IsZero() && o.Latest.Before(r.Earliest) {
+		return false
+	}
+	return true
+}
@@ -49,6 +49,8 @@ func (d *Decoder) Decode() (*Gedcom, error) {
 	d.parsers = []parser{makeRootParser(d, g)}
 	d.scan(g)
 
+	g.Place = indexPlaces(g)
+
 	return g, nil
 }
 
@@ -251,6 +253,7 @@ func makeRootParser(d *Decoder, g *Gedcom) parser {
 				d.pushParser(makeSourceParser(d, obj, level))
 			case "NOTE":
 				obj := d.note(xref)
+				obj.Note = value
 				g.Note = append(g.Note, obj)
 				d.pushParser(makeNoteParser(d, obj, level))
 			case "OBJE":
@@ -304,6 +307,39 @@ func makeAddressParser(d *Decoder, a *AddressRecord, minLevel int) parser {
 	}
 }
 
+func makeAssociationParser(d *Decoder, a *AssociationRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "RELA": // GEDCOM 5.5.1
+			a.Relation = value
+		case "ROLE": // GEDCOM 7.0
+			a.Relation = value
+		case "SOUR":
+			c := &CitationRecord{Source: d.source(stripXref(value))}
+			a.Citation = append(a.Citation, c)
+			d.pushParser(makeCitationParser(d, c, level))
+		case "NOTE":
+			if strings.HasPrefix(value, "@") {
+				r := d.note(stripXref(value))
+				a.Note = append(a.Note, r)
+			} else {
+				r := &NoteRecord{Note: value}
+				a.Note = append(a.Note, r)
+				d.pushParser(makeNoteParser(d, r, level))
+			}
+
+		default:
+			d.cbUnrecognizedTag(level, tag, value, xref)
+			d.pushParser(makeSlurkParser(d, level))
+		}
+
+		return nil
+	}
+}
+
 func makeChangedParser(d *Decoder, r *ChangedRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -384,7 +420,7 @@ func makeCitationParser(d *Decoder, c *CitationRecord, minLevel int) parser {
 		case "DATA":
 			d.pushParser(makeDataParser(d, &c.Data, level))
 		case "OBJE":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				o := d.object(stripXref(value))
 				c.Object = append(c.Object, o)
 			} else {
@@ -443,7 +479,7 @@ func makeEncodingParser(d *Decoder, e *EncodingRecord, minLevel int) parser {
 func makeEventParser(d *Decoder, e *EventRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
-			if e.Date == "" {
+			if e.Date == "" && e.SDate == "" {
 				e.SortDate = time.Now()
 			}
 			return d.popParser(level, tag, value, xref)
@@ -453,7 +489,39 @@ func makeEventParser(d *Decoder, e *EventRecord, minLevel int) parser {
 			e.Type = value
 		case "DATE":
 			e.Date = value
+			if e.SDate == "" {
+				e.SortDate = getSortDate(value)
+			}
+		case "SDATE": // GEDCOM 7.0, overrides DATE for sorting
+			e.SDate = value
 			e.SortDate = getSortDate(value)
+		case "AGE":
+			e.Age = value
+		case "AGNC":
+			e.Agency = value
+		case "RELI":
+			e.Religion = value
+		case "RESN":
+			e.Restriction = value
+		case "PHON":
+			e.Phone = append(e.Phone, value)
+		case "EMAIL":
+			e.Email = append(e.Email, value)
+		case "WWW":
+			e.Website = append(e.Website, value)
+		case "OBJE":
+			if strings.HasPrefix(value, "@") {
+				o := d.object(stripXref(value))
+				e.Object = append(e.Object, o)
+			} else {
+				o := &ObjectRecord{}
+				e.Object = append(e.Object, o)
+				d.pushParser(makeObjectParser(d, o, level))
+			}
+		case "ASSO":
+			a := &AssociationRecord{Person: d.individual(stripXref(value))}
+			e.Association = append(e.Association, a)
+			d.pushParser(makeAssociationParser(d, a, level))
 		case "PLAC":
 			e.Place.Name = value
 			d.pushParser(makePlaceParser(d, &e.Place, level))
@@ -465,7 +533,7 @@ func makeEventParser(d *Decoder, e *EventRecord, minLevel int) parser {
 			e.Citation = append(e.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "NOTE":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				r := d.note(stripXref(value))
 				e.Note = append(e.Note, r)
 			} else {
@@ -474,7 +542,7 @@ func makeEventParser(d *Decoder, e *EventRecord, minLevel int) parser {
 				d.pushParser(makeNoteParser(d, r, level))
 			}
 		case "CAUS":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				o := d.note(stripXref(value))
 				e.Cause = append(e.Cause, o)
 			} else {
@@ -551,12 +619,14 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 		case "NCHI":
 			f.NumberOfChildren = &EventRecord{Tag: tag, Value: value}
 			d.pushParser(makeEventParser(d, f.NumberOfChildren, level))
+		case "RESN":
+			f.Restriction = value
 		case "SOUR":
 			c := &CitationRecord{Source: d.source(stripXref(value))}
 			f.Citation = append(f.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "OBJE": // {0:M}
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				o := d.object(stripXref(value))
 				f.Object = append(f.Object, o)
 			} else {
@@ -565,7 +635,7 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 				d.pushParser(makeObjectParser(d, o, level))
 			}
 		case "NOTE":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				r := d.note(stripXref(value))
 				f.Note = append(f.Note, r)
 			} else {
@@ -573,6 +643,11 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 				f.Note = append(f.Note, r)
 				d.pushParser(makeNoteParser(d, r, level))
 			}
+		case "UID", "_UID":
+			f.UID = append(f.UID, value)
+		case "REFN":
+			f.UserReference = append(f.UserReference, value)
+			d.pushParser(makeSlurkParser(d, level))
 		case "CHAN":
 			f.Changed = &ChangedRecord{}
 			d.pushParser(makeChangedParser(d, f.Changed, level))
@@ -595,6 +670,7 @@ func makeFileParser(d *Decoder, f *FileRecord, minLevel int) parser {
 			f.Title = value
 		case "FORM":
 			f.Form = value
+			d.pushParser(makeFileFormParser(d, f, level))
 		case "_TEXT":
 			r := &NoteRecord{Note: value}
 			f.Description = r
@@ -609,6 +685,46 @@ func makeFileParser(d *Decoder, f *FileRecord, minLevel int) parser {
 	}
 }
 
+func makeFileFormParser(d *Decoder, f *FileRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "TYPE": // GEDCOM 5.5.1
+			f.MediaType = value
+		case "MEDI": // GEDCOM 7.0
+			f.MediaType = value
+
+		default:
+			d.cbUnrecognizedTag(level, tag, value, xref)
+			d.pushParser(makeSlurkParser(d, level))
+		}
+
+		return nil
+	}
+}
+
+func makeBlobParser(d *Decoder, o *ObjectRecord, minLevel int) parser {
+	var encoded []byte
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			o.Blob = decodeBlob(encoded)
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "CONT":
+			encoded = append(encoded, value...)
+
+		default:
+			d.cbUnrecognizedTag(level, tag, value, xref)
+			d.pushParser(makeSlurkParser(d, level))
+		}
+
+		return nil
+	}
+}
+
 func makeHeaderDataParser(d *Decoder, r *HeaderDataRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -677,6 +793,8 @@ func makeHeaderParser(d *Decoder, h *HeaderRecord, minLevel int) parser {
 		case "GEDC":
 			h.Info = &HeaderInfoRecord{}
 			d.pushParser(makeHeaderInfoParser(d, h.Info, level))
+		case "PLAC":
+			d.pushParser(makeHeaderPlaceParser(d, h, level))
 		case "NOTE":
 			h.Note = &NoteRecord{Note: value}
 			d.pushParser(makeNoteParser(d, h.Note, level))
@@ -689,6 +807,23 @@ func makeHeaderParser(d *Decoder, h *HeaderRecord, minLevel int) parser {
 	}
 }
 
+func makeHeaderPlaceParser(d *Decoder, h *HeaderRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "FORM":
+			h.PlaceForm = value
+
+		default:
+			d.cbUnrecognizedTag(level, tag, value, xref)
+			d.pushParser(makeSlurkParser(d, level))
+		}
+		return nil
+	}
+}
+
 func makeHeaderSourceParser(d *Decoder, r *HeaderSourceRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -756,10 +891,16 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 			c := &CitationRecord{Source: d.source(stripXref(value))}
 			i.Citation = append(i.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
+		case "ASSO":
+			a := &AssociationRecord{Person: d.individual(stripXref(value))}
+			i.Association = append(i.Association, a)
+			d.pushParser(makeAssociationParser(d, a, level))
+		case "RESN":
+			i.Restriction = value
 		case "_PHOTO":
 			i.Photo = d.object(stripXref(value))
 		case "OBJE": // {0:M}
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				o := d.object(stripXref(value))
 				i.Object = append(i.Object, o)
 			} else {
@@ -768,7 +909,7 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 				d.pushParser(makeObjectParser(d, o, level))
 			}
 		case "NOTE":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				r := d.note(stripXref(value))
 				i.Note = append(i.Note, r)
 			} else {
@@ -776,6 +917,11 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 				i.Note = append(i.Note, r)
 				d.pushParser(makeNoteParser(d, r, level))
 			}
+		case "UID", "_UID":
+			i.UID = append(i.UID, value)
+		case "REFN":
+			i.UserReference = append(i.UserReference, value)
+			d.pushParser(makeSlurkParser(d, level))
 		case "CHAN":
 			i.Changed = &ChangedRecord{}
 			d.pushParser(makeChangedParser(d, i.Changed, level))
@@ -843,14 +989,33 @@ func makeNoteParser(d *Decoder, n *NoteRecord, minLevel int) parser {
 func makeObjectParser(d *Decoder, o *ObjectRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
+			// GEDCOM 5.5 puts FORM and TITL alongside FILE rather than under it
+			for _, f := range o.File {
+				if f.Form == "" {
+					f.Form = o.Form
+				}
+				if f.Title == "" {
+					f.Title = o.Title
+				}
+			}
 			return d.popParser(level, tag, value, xref)
 		}
 		switch tag {
-		case "FILE":
-			o.File = &FileRecord{Name: value}
-			d.pushParser(makeFileParser(d, o.File, level))
+		case "FILE": // {1:M}
+			f := &FileRecord{Name: value}
+			o.File = append(o.File, f)
+			d.pushParser(makeFileParser(d, f, level))
+		case "FORM": // {0:1} GEDCOM 5.5
+			o.Form = value
+		case "TITL": // {0:1} GEDCOM 5.5
+			o.Title = value
+		case "BLOB": // {0:1} GEDCOM 5.5
+			d.pushParser(makeBlobParser(d, o, level))
+		case "CHAN": // {0:1}
+			o.Changed = &ChangedRecord{}
+			d.pushParser(makeChangedParser(d, o.Changed, level))
 		case "NOTE":
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				r := d.note(stripXref(value))
 				o.Note = append(o.Note, r)
 			} else {
@@ -883,6 +1048,34 @@ func makePlaceParser(d *Decoder, p *PlaceRecord, minLevel int) parser {
 			d.pushParser(makeNoteParser(d, r, level))
 		case "MAP":
 			d.pushParser(makeMapParser(d, p, level))
+		case "FORM":
+			p.Form = value
+		case "FONE":
+			v := &PlaceVariantRecord{Name: value}
+			p.Phonetic = append(p.Phonetic, v)
+			d.pushParser(makePlaceVariantParser(d, v, level))
+		case "ROMN":
+			v := &PlaceVariantRecord{Name: value}
+			p.Romanized = append(p.Romanized, v)
+			d.pushParser(makePlaceVariantParser(d, v, level))
+
+		default:
+			d.cbUnrecognizedTag(level, tag, value, xref)
+			d.pushParser(makeSlurkParser(d, level))
+		}
+
+		return nil
+	}
+}
+
+func makePlaceVariantParser(d *Decoder, v *PlaceVariantRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "TYPE":
+			v.Type = value
 
 		default:
 			d.cbUnrecognizedTag(level, tag, value, xref)
@@ -921,9 +1114,27 @@ func makeRepositoryParser(d *Decoder, o *RepositoryRecord, minLevel int) parser
 		}
 		switch tag {
 		case "NAME":
+			o.Name = value
 		case "ADDR":
-		case "EMAIL":
+			o.Address = &AddressRecord{Full: value}
+			d.pushParser(makeAddressParser(d, o.Address, level))
 		case "PHON":
+			o.Phone = append(o.Phone, value)
+		case "EMAIL":
+			o.Email = append(o.Email, value)
+		case "WWW":
+			o.Website = append(o.Website, value)
+		case "CHAN":
+			o.Changed = &ChangedRecord{}
+			d.pushParser(makeChangedParser(d, o.Changed, level))
+		case "NOTE":
+			if strings.HasPrefix(value, "@") {
+				o.Note = append(o.Note, d.note(stripXref(value)))
+			} else {
+				n := &NoteRecord{Note: value}
+				o.Note = append(o.Note, n)
+				d.pushParser(makeNoteParser(d, n, level))
+			}
 
 		default:
 			d.cbUnrecognizedTag(level, tag, value, xref)
@@ -982,6 +1193,11 @@ func makeSourceParser(d *Decoder, s *SourceRecord, minLevel int) parser {
 		case "TYPE": // {0:1}
 			s.Type = value
 			d.pushParser(makeTextParser(d, &s.Type, level))
+		case "UID", "_UID":
+			s.UID = append(s.UID, value)
+		case "REFN":
+			s.UserReference = append(s.UserReference, value)
+			d.pushParser(makeSlurkParser(d, level))
 		case "CHAN": // {0:1}
 			s.Changed = &ChangedRecord{}
 			d.pushParser(makeChangedParser(d, s.Changed, level))
@@ -990,7 +1206,7 @@ func makeSourceParser(d *Decoder, s *SourceRecord, minLevel int) parser {
 			s.Note = append(s.Note, r)
 			d.pushParser(makeNoteParser(d, r, level))
 		case "OBJE": // {0:M}
-			if value[0:1] == "@" {
+			if strings.HasPrefix(value, "@") {
 				o := d.object(stripXref(value))
 				s.Object = append(s.Object, o)
 			} else {
@@ -125,8 +125,10 @@ func TestIndividual(t *testing.T) {
 				Object: []*ObjectRecord{
 					{
 						Xref: "M794",
-						File: &FileRecord{
-							Form: "gif",
+						File: []*FileRecord{
+							{
+								Form: "gif",
+							},
 						},
 					},
 				},
@@ -208,7 +210,7 @@ func TestIndividual(t *testing.T) {
 		{"Individual 0 Name citation Title", name1.Citation[0].Source.Title, i1.Name[0].Citation[0].Source.Title},
 		{"Individual 0 Name citation Author", name1.Citation[0].Source.Author, i1.Name[0].Citation[0].Source.Author},
 		{"Individual 0 Name citation object", name1.Citation[0].Object[0].Xref, i1.Name[0].Citation[0].Object[0].Xref},
-		{"Individual 0 Name citation object form", name1.Citation[0].Object[0].File.Form, i1.Name[0].Citation[0].Object[0].File.Form},
+		{"Individual 0 Name citation object form", name1.Citation[0].Object[0].File[0].Form, i1.Name[0].Citation[0].Object[0].File[0].Form},
 		{"Individual 0 Note 0", name1.Note[0].Note, i1.Name[0].Note[0].Note},
 		{"Individual 0 Birth Tag", birth.Tag, i1.Event[0].Tag},
 		{"Individual 0 Birth Date", birth.Date, i1.Event[0].Date},
@@ -233,9 +235,9 @@ func TestIndividual(t *testing.T) {
 		{"Individual 0 citation page", "42", i1.Citation[0].Page},
 		{"Individual 0 citation source author", "Author of source", i1.Citation[0].Source.Author[:16]},
 		{"Individual 0 name citation page", "Roll 1066, Pg 369B, 1880 US Census, 1880 Census, Ohio, Seneca County,  (Downloaded from Genealogy.com, supplemented by LDS site), Roll 1066, Pg 369B.", i1.Name[0].Citation[0].Page},
-		{"Individual 0 object 0 title", "A gif picture", i1.Object[0].File.Title},
-		{"Individual 0 object 1 form", "jpg", i1.Object[1].File.Form},
-		{"Individual 0 photo name", "/Users/test/test.jpg", i1.Photo.File.Name},
+		{"Individual 0 object 0 title", "A gif picture", i1.Object[0].File[0].Title},
+		{"Individual 0 object 1 form", "jpg", i1.Object[1].File[0].Form},
+		{"Individual 0 photo name", "/Users/test/test.jpg", i1.Photo.File[0].Name},
 		{"Individual 0 Note 0", "A note about the individual\nNote continued here. The word TEST should not be broken!", i1.Note[0].Note},
 		{"Individual 0 change date", "1 APR 1998", i1.Changed.Stamp.Date},
 		{"Individual 0 change time", "12:34:56.789", i1.Changed.Stamp.Time},
@@ -302,7 +304,7 @@ func TestFamily(t *testing.T) {
 		{"Number of children", "42", f[0].NumberOfChildren.Value},
 		{"Family citation quality", "0", f[0].Citation[0].Quality},
 		{"Family citation first file", "file1", f[0].Citation[0].Source.File[0]},
-		{"Family object title", "A jpg picture", f[0].Object[0].File.Title},
+		{"Family object title", "A jpg picture", f[0].Object[0].File[0].Title},
 		{"Family note 0", "A note about the family\nNote continued here. The word TEST should not be broken!", f[0].Note[0].Note},
 		{"Family change date", "1 APR 1998", f[0].Changed.Stamp.Date},
 		{"Family change time", "12:34:56.789", f[0].Changed.Stamp.Time},
@@ -336,7 +338,7 @@ func TestSource(t *testing.T) {
 		{"Source volume", "1", s.Volume},
 		{"Source page", "3", s.Page[0]},
 		{"Source film reference", "at 11", s.Film[0]},
-		{"Source object 0 title", "A bmp picture", s.Object[0].File.Title},
+		{"Source object 0 title", "A bmp picture", s.Object[0].File[0].Title},
 		{"Source event data responsible agency", "Responsible agency", s.EventData.Agency},
 		{"Source event data note", "A note about whatever\nNote continued here. The word TEST should not be broken!", s.EventData.Note[0].Note},
 		{"Source birth and christening event tags", "BIRT, CHR", s.EventData.Event[0].Value},
@@ -350,12 +352,12 @@ func TestObject(t *testing.T) {
 
 	stringTestCases{
 		{"First file Xref", "M794", objects[0].Xref},
-		{"First object file form", "gif", objects[0].File.Form},
+		{"First object file form", "gif", objects[0].File[0].Form},
 		{"First object note 0", "A note about the object\nNote continued here. The word TEST should not be broken!", objects[0].Note[0].Note},
-		{"Second object file name", "/Users/test/test.jpg", objects[1].File.Name},
+		{"Second object file name", "/Users/test/test.jpg", objects[1].File[0].Name},
 		{"Second object note 0", "\nObject note here. The word TEST should not be broken!", objects[1].Note[0].Note},
-		{"Third object Title", "A bmp picture", objects[2].File.Title},
-		{"Third object Description", "Description of this fine BMP", objects[2].File.Description.Note},
+		{"Third object Title", "A bmp picture", objects[2].File[0].Title},
+		{"Third object Description", "Description of this fine BMP", objects[2].File[0].Description.Note},
 	}.run(t)
 }
 
@@ -407,3 +409,59 @@ func TestUTF8BOM(t *testing.T) {
 		{"Header Name", "FTM", g.Header.Destination},
 	}.run(t)
 }
+
+func TestEventDetail(t *testing.T) {
+
+	eg := decodeFile(t, "testdata/events.ged")
+
+	i := eg.Individual[0]
+	cens := i.Event[0]
+	occu := i.Attribute[0]
+
+	intTestCases{
+		{"Census object count was [%d]", 2, len(cens.Object)},
+		{"Census association count was [%d]", 1, len(cens.Association)},
+		{"Individual association count was [%d]", 1, len(i.Association)},
+	}.run(t)
+
+	stringTestCases{
+		{"Individual restriction", "privacy", i.Restriction},
+		{"Individual association person", "I2", i.Association[0].Person.Xref},
+		{"Individual association relation", "Godfather", i.Association[0].Relation},
+		{"Individual association note", "Named in baptism register", i.Association[0].Note[0].Note},
+		{"Census date", "1881", cens.Date},
+		{"Census sort date", "3 APR 1881", cens.SDate},
+		{"Census age", "23y", cens.Age},
+		{"Census agency", "Registrar General", cens.Agency},
+		{"Census religion", "Methodist", cens.Religion},
+		{"Census restriction", "confidential", cens.Restriction},
+		{"Census phone", "+44 1234 567890", cens.Phone[0]},
+		{"Census email", "census@example.com", cens.Email[0]},
+		{"Census website", "https://example.com/census/1881", cens.Website[0]},
+		{"Census linked object", "census-1881.jpg", cens.Object[0].File[0].Name},
+		{"Census embedded object", "census.jpg", cens.Object[1].File[0].Name},
+		{"Census association person", "Peter /Jones/", cens.Association[0].Person.Name[0].Name},
+		{"Census association role", "WITN", cens.Association[0].Relation},
+		{"Occupation age", "23y", occu.Age},
+		{"Occupation agency", "Smith and Sons", occu.Agency},
+	}.run(t)
+
+	if cens.SortDate != time.Date(1881, time.April, 3, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Census sort date was [%v], expected SDATE to take precedence", cens.SortDate)
+	}
+}
+
+func TestNoteRecord(t *testing.T) {
+
+	ng := decodeFile(t, "testdata/notes.ged")
+
+	intTestCases{
+		{"Note list length was [%d]", 3, len(ng.Note)},
+	}.run(t)
+
+	stringTestCases{
+		{"Note 0 text", "Farmer", ng.Note[0].Note},
+		{"Note 1 text", "Farmer\nLater a miller", ng.Note[1].Note},
+		{"Note 2 text", "Started on the second line", ng.Note[2].Note},
+	}.run(t)
+}
@@ -0,0 +1,613 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The operations of a Change.
+const (
+	ChangeAdd     = "add"
+	ChangeRemove  = "remove"
+	ChangeReplace = "replace"
+)
+
+// Change is a single difference between two files: a record or field that
+// was added or removed, or a field whose value was replaced.
+//
+// The Path of a Change is made of the tags leading from the record to the
+// field, separated by spaces, such as "BIRT DATE". A tag that occurs more
+// than once is numbered from the second, so "RESI[2] PLAC" is the place of
+// the second residence. The Path of a change to the record itself, or to the
+// text of a NOTE record, is empty.
+type Change struct {
+	Op     string `json:"op"`
+	Type   string `json:"type"`   // INDI, FAM, SOUR, REPO, NOTE, OBJE or SUBM
+	Record string `json:"record"` // the record's xref in the old file, or as added
+	Path   string `json:"path,omitempty"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+func (c *Change) String() string {
+	what := c.Record + " " + c.Path
+	if c.Path == "" {
+		what = c.Record + " " + c.Type
+	}
+	switch c.Op {
+	case ChangeReplace:
+		return fmt.Sprintf("%s changed from %s to %s", what, c.Old, c.New)
+	case ChangeAdd:
+		if c.New != "" {
+			return fmt.Sprintf("%s added: %s", what, c.New)
+		}
+		return what + " added"
+	case ChangeRemove:
+		if c.Old != "" {
+			return fmt.Sprintf("%s removed: %s", what, c.Old)
+		}
+		return what + " removed"
+	}
+	return what
+}
+
+// ChangeSet is the list of changes that turn one file into another.
+type ChangeSet struct {
+	Changes []*Change `json:"changes"`
+}
+
+// String describes the changes, one per line.
+func (cs ChangeSet) String() string {
+	lines := make([]string, len(cs.Changes))
+	for i, c := range cs.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Patch encodes the changes as JSON.
+func (cs ChangeSet) Patch() ([]byte, error) {
+	return json.MarshalIndent(cs, "", "  ")
+}
+
+// diffKinds lists the kinds of record compared by Diff in the order their
+// changes are given. Records are matched kind by kind in the same order, so
+// individuals are matched before the families that refer to them.
+var diffKinds = []struct {
+	tag     string
+	field   string
+	similar func(d *differ, a reflect.Value, b reflect.Value) int
+}{
+	{"INDI", "Individual", similarIndividuals},
+	{"FAM", "Family", similarFamilies},
+	{"SOUR", "Source", similarSources},
+	{"REPO", "Repository", similarRepositories},
+	{"NOTE", "Note", similarNotes},
+	{"OBJE", "Object", similarObjects},
+	{"SUBM", "Submitter", similarSubmitters},
+}
+
+// Diff returns the changes that turn before into after. Records are matched
+// by UID, then by REFN, then by xref, and the rest by the similarity of their
+// contents. Matched records are compared field by field, and the records of
+// after left unmatched are added, renumbered if their xrefs are used in
+// before. Change stamps are not compared.
+func Diff(before *Gedcom, after *Gedcom) ChangeSet {
+	d := &differ{
+		match: make(map[interface{}]reflect.Value),
+		xref:  make(map[string]string),
+		added: make(map[interface{}]string),
+	}
+	b := reflect.ValueOf(before).Elem()
+	a := reflect.ValueOf(after).Elem()
+
+	keys := []func(reflect.Value) []string{
+		stringsField("UID"),
+		stringsField("UserReference"),
+		func(v reflect.Value) []string { return []string{recordXref(v)} },
+	}
+	for _, key := range keys {
+		for _, k := range diffKinds {
+			d.matchByKey(b.FieldByName(k.field), a.FieldByName(k.field), key)
+		}
+	}
+	for _, k := range diffKinds {
+		d.matchBySimilarity(b.FieldByName(k.field), a.FieldByName(k.field), k.similar)
+	}
+	d.numberAdded(before, after)
+
+	counterpart := make(map[interface{}]reflect.Value)
+	for n, o := range d.match {
+		counterpart[o.Interface()] = reflect.ValueOf(n)
+	}
+
+	var cs ChangeSet
+	for _, k := range diffKinds {
+		bl, al := b.FieldByName(k.field), a.FieldByName(k.field)
+		for i := 0; i < bl.Len(); i++ {
+			o := bl.Index(i)
+			if o.IsNil() {
+				continue
+			}
+			n, ok := counterpart[o.Interface()]
+			if !ok {
+				cs.Changes = append(cs.Changes, &Change{Op: ChangeRemove, Type: k.tag, Record: recordXref(o), Old: recordValue(o)})
+				continue
+			}
+			cs.Changes = append(cs.Changes, diffLines(k.tag, recordXref(o), flattenRecord(o, nil), flattenRecord(n, d.xref))...)
+		}
+		for i := 0; i < al.Len(); i++ {
+			n := al.Index(i)
+			if _, ok := d.match[n.Interface()]; n.IsNil() || ok {
+				continue
+			}
+			cs.Changes = append(cs.Changes, diffLines(k.tag, d.added[n.Interface()], nil, flattenRecord(n, d.xref))...)
+		}
+	}
+	return cs
+}
+
+// differ holds the records of the new file matched to those of the old.
+type differ struct {
+	match map[interface{}]reflect.Value // records of the new file to those of the old
+	xref  map[string]string             // xrefs of the new file to those of the old, or as added
+	added map[interface{}]string        // unmatched records of the new file to their xrefs as added
+}
+
+// matchByKey matches the unmatched records of before and after, lists of
+// records, that share a non-empty key.
+func (d *differ) matchByKey(before reflect.Value, after reflect.Value, key func(reflect.Value) []string) {
+	taken := d.taken()
+	byKey := make(map[string]reflect.Value)
+	for i := 0; i < before.Len(); i++ {
+		o := before.Index(i)
+		if o.IsNil() || taken[o.Interface()] {
+			continue
+		}
+		for _, k := range key(o) {
+			if _, ok := byKey[k]; k != "" && !ok {
+				byKey[k] = o
+			}
+		}
+	}
+	for i := 0; i < after.Len(); i++ {
+		n := after.Index(i)
+		if n.IsNil() {
+			continue
+		}
+		if _, ok := d.match[n.Interface()]; ok {
+			continue
+		}
+		for _, k := range key(n) {
+			if o, ok := byKey[k]; ok && k != "" && !taken[o.Interface()] {
+				d.match[n.Interface()] = o
+				taken[o.Interface()] = true
+				break
+			}
+		}
+	}
+}
+
+// matchBySimilarity matches each unmatched record of after to the unmatched
+// record of before it is most similar to, if any.
+func (d *differ) matchBySimilarity(before reflect.Value, after reflect.Value, similar func(*differ, reflect.Value, reflect.Value) int) {
+	taken := d.taken()
+	for i := 0; i < after.Len(); i++ {
+		n := after.Index(i)
+		if n.IsNil() {
+			continue
+		}
+		if _, ok := d.match[n.Interface()]; ok {
+			continue
+		}
+		best, bestScore := reflect.Value{}, 0
+		for j := 0; j < before.Len(); j++ {
+			o := before.Index(j)
+			if o.IsNil() || taken[o.Interface()] {
+				continue
+			}
+			if s := similar(d, o, n); s > bestScore {
+				best, bestScore = o, s
+			}
+		}
+		if best.IsValid() {
+			d.match[n.Interface()] = best
+			taken[best.Interface()] = true
+		}
+	}
+}
+
+// taken returns the records of the old file already matched.
+func (d *differ) taken() map[interface{}]bool {
+	taken := make(map[interface{}]bool)
+	for _, o := range d.match {
+		taken[o.Interface()] = true
+	}
+	return taken
+}
+
+// numberAdded gives the records of after their xrefs in the change set: the
+// xref of the record they match, their own if before does not use it, or
+// else a new one. Records of kinds Diff does not compare keep their own.
+func (d *differ) numberAdded(before *Gedcom, after *Gedcom) {
+	alloc := newXrefAllocator()
+	for _, rec := range before.xrefRecords() {
+		alloc.use(recordXref(rec))
+	}
+
+	compared := make(map[reflect.Type]bool)
+	for _, k := range diffKinds {
+		f, _ := reflect.TypeOf(Gedcom{}).FieldByName(k.field)
+		compared[f.Type.Elem()] = true
+	}
+
+	var clashes []reflect.Value
+	for _, rec := range after.xrefRecords() {
+		x := recordXref(rec)
+		if !compared[rec.Type()] {
+			d.xref[x] = x
+			continue
+		}
+		if o, ok := d.match[rec.Interface()]; ok {
+			d.xref[x] = recordXref(o)
+			continue
+		}
+		if x == "" || alloc.used[x] {
+			clashes = append(clashes, rec)
+			continue
+		}
+		alloc.use(x)
+		d.xref[x] = x
+		d.added[rec.Interface()] = x
+	}
+	for _, rec := range clashes {
+		x := recordXref(rec)
+		nx := alloc.allocate(xrefPrefix(rec, x))
+		if x != "" {
+			d.xref[x] = nx
+		}
+		d.added[rec.Interface()] = nx
+	}
+}
+
+// counterpart returns the record of the old file matched to rec of the new,
+// or nil.
+func (d *differ) counterpart(rec interface{}) interface{} {
+	if m, ok := d.match[rec]; ok {
+		return m.Interface()
+	}
+	return nil
+}
+
+func similarIndividuals(d *differ, a reflect.Value, b reflect.Value) int {
+	c := CompareIndividuals(a.Interface().(*IndividualRecord), b.Interface().(*IndividualRecord))
+	if c == nil || c.Score < DefaultAutoMergeScore {
+		return 0
+	}
+	return c.Score
+}
+
+func similarFamilies(d *differ, a reflect.Value, b reflect.Value) int {
+	fa, fb := a.Interface().(*FamilyRecord), b.Interface().(*FamilyRecord)
+	if fb.Husband == nil && fb.Wife == nil {
+		return 0
+	}
+	if (fa.Husband == nil) != (fb.Husband == nil) || (fa.Wife == nil) != (fb.Wife == nil) {
+		return 0
+	}
+	if fb.Husband != nil && d.counterpart(fb.Husband) != fa.Husband {
+		return 0
+	}
+	if fb.Wife != nil && d.counterpart(fb.Wife) != fa.Wife {
+		return 0
+	}
+	return 1
+}
+
+func similarSources(d *differ, a reflect.Value, b reflect.Value) int {
+	sa, sb := a.Interface().(*SourceRecord), b.Interface().(*SourceRecord)
+	return sameKey(contentKey(sa.Title, sa.Author, sa.Publication), contentKey(sb.Title, sb.Author, sb.Publication))
+}
+
+func similarRepositories(d *differ, a reflect.Value, b reflect.Value) int {
+	return sameKey(repositoryKey(a.Interface().(*RepositoryRecord)), repositoryKey(b.Interface().(*RepositoryRecord)))
+}
+
+func similarNotes(d *differ, a reflect.Value, b reflect.Value) int {
+	return sameKey(contentKey(a.Interface().(*NoteRecord).Note), contentKey(b.Interface().(*NoteRecord).Note))
+}
+
+func similarObjects(d *differ, a reflect.Value, b reflect.Value) int {
+	return sameKey(objectKey(a.Interface().(*ObjectRecord)), objectKey(b.Interface().(*ObjectRecord)))
+}
+
+func similarSubmitters(d *differ, a reflect.Value, b reflect.Value) int {
+	return sameKey(contentKey(a.Interface().(*SubmitterRecord).Name), contentKey(b.Interface().(*SubmitterRecord).Name))
+}
+
+// objectKey identifies a multimedia object by its title and the names of its
+// files.
+func objectKey(o *ObjectRecord) string {
+	parts := []string{o.Title}
+	for _, f := range o.File {
+		parts = append(parts, f.Name)
+	}
+	return contentKey(parts...)
+}
+
+func sameKey(a string, b string) int {
+	if a != "" && a == b {
+		return 1
+	}
+	return 0
+}
+
+// stringsField returns a function giving the []string field of a record
+// with the given name, or nothing if it has none.
+func stringsField(name string) func(reflect.Value) []string {
+	return func(v reflect.Value) []string {
+		if f := v.Elem().FieldByName(name); f.IsValid() && f.Kind() == reflect.Slice {
+			return f.Interface().([]string)
+		}
+		return nil
+	}
+}
+
+// diffStructure describes how Diff compares a kind of structure: the field
+// holding its own value, if any, and its substructures by tag.
+type diffStructure struct {
+	value  string
+	fields []diffField
+}
+
+// diffField is a field of a structure and its tag. Structures in a field
+// without a tag take their tag from their own Tag field.
+type diffField struct {
+	tag  string
+	name string
+}
+
+var diffStructures = map[reflect.Type]diffStructure{
+	reflect.TypeOf(IndividualRecord{}): {"", []diffField{
+		{"UID", "UID"}, {"REFN", "UserReference"}, {"NAME", "Name"}, {"SEX", "Sex"},
+		{"", "Event"}, {"", "Attribute"}, {"FAMC", "Parents"}, {"FAMS", "Family"},
+		{"ASSO", "Association"}, {"RESN", "Restriction"}, {"SOUR", "Citation"},
+		{"OBJE", "Object"}, {"_PHOTO", "Photo"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(FamilyRecord{}): {"", []diffField{
+		{"UID", "UID"}, {"REFN", "UserReference"}, {"HUSB", "Husband"}, {"WIFE", "Wife"},
+		{"CHIL", "Child"}, {"NCHI", "NumberOfChildren"}, {"", "Event"},
+		{"RESN", "Restriction"}, {"SOUR", "Citation"}, {"OBJE", "Object"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(SourceRecord{}): {"", []diffField{
+		{"UID", "UID"}, {"REFN", "UserReference"}, {"AUTH", "Author"}, {"TITL", "Title"},
+		{"ABBR", "Abbr"}, {"PUBL", "Publication"}, {"TYPE", "Type"}, {"TEXT", "Text"},
+		{"MEDI", "MediaType"}, {"PERI", "Periodical"}, {"VOL", "Volume"}, {"PAGE", "Page"},
+		{"FILM", "Film"}, {"FILE", "File"}, {"FILN", "FileNumber"}, {"DATE", "Date"},
+		{"PLAC", "Place"}, {"DATV", "DateViewed"}, {"URL", "URL"}, {"LOCA", "DocLocation"},
+		{"REPO", "Repository"}, {"SUBM", "Submitter"}, {"DATA", "EventData"},
+		{"NOTE", "Note"}, {"OBJE", "Object"},
+	}},
+	reflect.TypeOf(RepositoryRecord{}): {"", []diffField{
+		{"NAME", "Name"}, {"ADDR", "Address"}, {"PHON", "Phone"}, {"EMAIL", "Email"},
+		{"WWW", "Website"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(NoteRecord{}): {"Note", []diffField{
+		{"SOUR", "Citation"},
+	}},
+	reflect.TypeOf(NameRecord{}): {"Name", []diffField{
+		{"NPFX", "Prefix"}, {"NSFX", "Suffix"}, {"SOUR", "Citation"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(EventRecord{}): {"Value", []diffField{
+		{"TYPE", "Type"}, {"DATE", "Date"}, {"PLAC", "Place"}, {"ADDR", "Address"},
+		{"PHON", "Phone"}, {"EMAIL", "Email"}, {"WWW", "Website"}, {"AGE", "Age"},
+		{"AGNC", "Agency"}, {"RELI", "Religion"}, {"CAUS", "Cause"}, {"RESN", "Restriction"},
+		{"FAMC", "Parents"}, {"ASSO", "Association"}, {"SOUR", "Citation"},
+		{"OBJE", "Object"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(PlaceRecord{}): {"Name", []diffField{
+		{"FORM", "Form"}, {"LATI", "Latitude"}, {"LONG", "Longitude"},
+		{"FONE", "Phonetic"}, {"ROMN", "Romanized"}, {"SOUR", "Citation"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(PlaceVariantRecord{}): {"Name", []diffField{
+		{"TYPE", "Type"},
+	}},
+	reflect.TypeOf(AddressRecord{}): {"Full", []diffField{
+		{"ADR1", "Line1"}, {"ADR2", "Line2"}, {"CITY", "City"}, {"STAE", "State"},
+		{"POST", "PostalCode"}, {"CTRY", "Country"}, {"PHON", "Phone"},
+	}},
+	reflect.TypeOf(CitationRecord{}): {"Source", []diffField{
+		{"PAGE", "Page"}, {"DATA", "Data"}, {"QUAY", "Quality"}, {"OBJE", "Object"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(DataRecord{}): {"", []diffField{
+		{"DATE", "Date"}, {"TEXT", "Text"},
+	}},
+	reflect.TypeOf(SourceDataRecord{}): {"", []diffField{
+		{"", "Event"}, {"AGNC", "Agency"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(FamilyLinkRecord{}): {"Family", []diffField{
+		{"PEDI", "Pedigree"}, {"ADOP", "AdoptedBy"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(ChildRecord{}): {"Person", []diffField{
+		{"_FREL", "FatherRelation"}, {"_MREL", "MotherRelation"},
+	}},
+	reflect.TypeOf(AssociationRecord{}): {"Person", []diffField{
+		{"RELA", "Relation"}, {"SOUR", "Citation"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(ObjectRecord{}): {"", []diffField{
+		{"FORM", "Form"}, {"TITL", "Title"}, {"FILE", "File"}, {"NOTE", "Note"},
+	}},
+	reflect.TypeOf(SubmitterRecord{}): {"", []diffField{
+		{"NAME", "Name"}, {"ADDR", "Address"}, {"PHON", "Phone"}, {"LANG", "Language"},
+	}},
+	reflect.TypeOf(FileRecord{}): {"Name", []diffField{
+		{"FORM", "Form"}, {"MEDI", "MediaType"}, {"TITL", "Title"}, {"_TEXT", "Description"},
+	}},
+}
+
+// diffLine is a field of a record and its value.
+type diffLine struct {
+	path  string
+	value string
+}
+
+// flattenRecord lists the fields of the record rec, starting with the record
+// itself. Xrefs are given as mapped by xrefs, if not nil.
+func flattenRecord(rec reflect.Value, xrefs map[string]string) []diffLine {
+	lines := []diffLine{{"", recordValue(rec)}}
+	return flattenFields(rec.Elem(), "", xrefs, lines)
+}
+
+// flattenFields appends the fields of the structure s below path to lines.
+func flattenFields(s reflect.Value, path string, xrefs map[string]string, lines []diffLine) []diffLine {
+	count := make(map[string]int)
+	for _, f := range diffStructures[s.Type()].fields {
+		v := s.FieldByName(f.name)
+		n := 1
+		if v.Kind() == reflect.Slice {
+			n = v.Len()
+		}
+		for i := 0; i < n; i++ {
+			e := v
+			if v.Kind() == reflect.Slice {
+				e = v.Index(i)
+			}
+			tag := f.tag
+			if tag == "" {
+				tag = reflect.Indirect(e).FieldByName("Tag").String()
+			}
+			count[tag]++
+			p := strings.TrimSpace(path + " " + pathSegment(tag, count[tag]))
+			lines = flattenValue(e, p, v.Kind() == reflect.Slice, xrefs, lines)
+		}
+	}
+	return lines
+}
+
+// flattenValue appends v and its fields to lines. Empty values are left out
+// unless they are elements of lists, which are numbered by position.
+func flattenValue(v reflect.Value, path string, element bool, xrefs map[string]string, lines []diffLine) []diffLine {
+	switch {
+	case v.Kind() == reflect.String:
+		if v.String() != "" || element {
+			lines = append(lines, diffLine{path, mapPointer(v.String(), xrefs)})
+		}
+		return lines
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		return lines
+	case v.Kind() == reflect.Ptr && recordXref(v) != "":
+		return append(lines, diffLine{path, pointerValue(v, xrefs)})
+	case v.Kind() == reflect.Ptr:
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return lines
+	}
+
+	value := ""
+	if name := diffStructures[v.Type()].value; name != "" {
+		value = scalarValue(v.FieldByName(name), xrefs)
+	}
+	sub := flattenFields(v, path, xrefs, nil)
+	if value != "" || element || len(sub) > 0 {
+		lines = append(lines, diffLine{path, value})
+	}
+	return append(lines, sub...)
+}
+
+// recordValue returns the value of the record rec itself, which only NOTE
+// records have.
+func recordValue(rec reflect.Value) string {
+	if name := diffStructures[rec.Elem().Type()].value; name != "" {
+		return rec.Elem().FieldByName(name).String()
+	}
+	return ""
+}
+
+// scalarValue returns a string or a pointer to a record as a string.
+func scalarValue(v reflect.Value, xrefs map[string]string) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		return pointerValue(v, xrefs)
+	}
+	return mapPointer(v.String(), xrefs)
+}
+
+// pointerValue returns a pointer to the record rec, such as @I1@.
+func pointerValue(rec reflect.Value, xrefs map[string]string) string {
+	return mapPointer("@"+recordXref(rec)+"@", xrefs)
+}
+
+// mapPointer maps the xref in a pointer such as @I1@ by xrefs. Other values
+// are returned unchanged.
+func mapPointer(value string, xrefs map[string]string) string {
+	if x := stripXref(value); x != value && xrefs != nil {
+		if nx, ok := xrefs[x]; ok {
+			return "@" + nx + "@"
+		}
+	}
+	return value
+}
+
+func pathSegment(tag string, n int) string {
+	if n == 1 {
+		return tag
+	}
+	return tag + "[" + strconv.Itoa(n) + "]"
+}
+
+// diffLines returns the changes that turn the fields before of a record into
+// those after. Fields within a removed field are not listed.
+func diffLines(kind string, xref string, before []diffLine, after []diffLine) []*Change {
+	bv := make(map[string]string, len(before))
+	for _, l := range before {
+		bv[l.path] = l.value
+	}
+	av := make(map[string]string, len(after))
+	for _, l := range after {
+		av[l.path] = l.value
+	}
+
+	var changes []*Change
+	removed := make(map[string]bool)
+	for _, l := range before {
+		v, ok := av[l.path]
+		switch {
+		case !ok:
+			if !withinRemoved(l.path, removed) {
+				changes = append(changes, &Change{Op: ChangeRemove, Type: kind, Record: xref, Path: l.path, Old: l.value})
+			}
+			removed[l.path] = true
+		case v != l.value:
+			changes = append(changes, &Change{Op: ChangeReplace, Type: kind, Record: xref, Path: l.path, Old: l.value, New: v})
+		}
+	}
+	for _, l := range after {
+		if _, ok := bv[l.path]; !ok {
+			changes = append(changes, &Change{Op: ChangeAdd, Type: kind, Record: xref, Path: l.path, New: l.value})
+		}
+	}
+	return changes
+}
+
+// withinRemoved reports whether a field containing the one at path is in
+// removed.
+func withinRemoved(path string, removed map[string]bool) bool {
+	for i := strings.LastIndex(path, " "); i >= 0; i = strings.LastIndex(path, " ") {
+		path = path[:i]
+		if removed[path] {
+			return true
+		}
+	}
+	return false
+}
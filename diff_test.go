@@ -0,0 +1,77 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before, after := decodeFile(t, "testdata/original.ged"), decodeFile(t, "testdata/revised.ged")
+
+	cs := Diff(before, after)
+
+	stringTestCases{
+		{"Changes", "I1 BIRT DATE changed from 12 MAR 1800 to ABT 1801\n" +
+			"I1 BIRT PLAC added: Kirkby\n" +
+			"I3 OCCU added: Farmer\n" +
+			"I4 INDI added\n" +
+			"I4 NAME added: Jane /Grant/\n" +
+			"I4 SEX added: F\n" +
+			"I4 BIRT added\n" +
+			"I4 BIRT DATE added: 1835\n" +
+			"I4 FAMC added: @F1@\n" +
+			"F1 CHIL[2] added: @I4@\n" +
+			"S1 REPO removed: @R1@\n" +
+			"R1 REPO removed\n" +
+			"N1 NOTE changed from Family bible transcribed in 1990 to Family bible transcribed in 1991", cs.String()},
+	}.run(t)
+
+	patch, err := cs.Patch()
+	if err != nil {
+		t.Fatalf("Patch gave error %v, expected no error", err)
+	}
+	var decoded ChangeSet
+	if err := json.Unmarshal(patch, &decoded); err != nil {
+		t.Fatalf("Unmarshal gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Decoded patch", cs.String(), decoded.String()},
+		{"First op", ChangeReplace, decoded.Changes[0].Op},
+		{"First path", "BIRT DATE", decoded.Changes[0].Path},
+	}.run(t)
+
+	intTestCases{
+		{"Changes from a file to itself was [%d]", 0, len(Diff(before, before).Changes)},
+	}.run(t)
+}
+
+func TestDiffObjectsAndSubmitters(t *testing.T) {
+	before, after := decodeFile(t, "testdata/original.ged"), decodeFile(t, "testdata/original.ged")
+	portrait := &ObjectRecord{Xref: "M1", File: []*FileRecord{{Name: "portrait.jpg", Form: "jpg"}}}
+	after.Object = append(after.Object, portrait)
+	after.Individual[0].Object = append(after.Individual[0].Object, portrait)
+	after.Submitter[0].Name = "Alice Researcher-Smith"
+
+	cs := Diff(before, after)
+
+	stringTestCases{
+		{"Changes", "I1 OBJE added: @M1@\n" +
+			"M1 OBJE added\n" +
+			"M1 FILE added: portrait.jpg\n" +
+			"M1 FILE FORM added: jpg\n" +
+			"U1 NAME changed from Alice Researcher to Alice Researcher-Smith", cs.String()},
+	}.run(t)
+
+	if err := Apply(before, cs); err != nil {
+		t.Fatalf("Apply gave error %v, expected no error", err)
+	}
+	stringTestCases{
+		{"Added object", "portrait.jpg", before.Individual[0].Object[0].File[0].Name},
+		{"Submitter name", "Alice Researcher-Smith", before.Submitter[0].Name},
+	}.run(t)
+}
@@ -0,0 +1,280 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDuplicateScore is the score at which FindDuplicates reports a pair
+// when no other minimum is given.
+const DefaultDuplicateScore = 50
+
+// DuplicateCandidate is a pair of individuals that may be the same person.
+type DuplicateCandidate struct {
+	A, B   *IndividualRecord
+	Score  int
+	Reason []*DuplicateReason // what contributed to Score, largest first
+}
+
+// DuplicateReason is one part of a duplicate score.
+type DuplicateReason struct {
+	Points int
+	Reason string
+}
+
+func (r *DuplicateReason) String() string {
+	return fmt.Sprintf("%+d %s", r.Points, r.Reason)
+}
+
+// Points awarded or deducted when comparing two individuals.
+const (
+	pointsNameExact    = 30
+	pointsNameSimilar  = 20
+	pointsNameInitial  = 5
+	pointsNameDiffer   = -20
+	pointsDateExact    = 20
+	pointsDateOverlap  = 10
+	pointsDateConflict = -30
+	pointsPlaceExact   = 10
+	pointsPlacePart    = 5
+	pointsSameRelative = 25
+	pointsSimilarName  = 10
+)
+
+// FindDuplicates compares the individuals in g that share a phonetic surname
+// or given name and returns the pairs scoring at least minScore, best first.
+// A minScore of zero or less means DefaultDuplicateScore. Individuals of
+// different recorded sex are never paired.
+func FindDuplicates(g *Gedcom, minScore int) []*DuplicateCandidate {
+	if minScore <= 0 {
+		minScore = DefaultDuplicateScore
+	}
+
+	blocks := make(map[string][]*IndividualRecord)
+	for _, ind := range g.Individual {
+		keys := make(map[string]bool)
+		for _, n := range ind.Name {
+			if s := Soundex(n.Surname()); s != "" {
+				keys["s"+s] = true
+			}
+			if s := Soundex(firstWord(n.Given())); s != "" {
+				keys["g"+s] = true
+			}
+		}
+		for k := range keys {
+			blocks[k] = append(blocks[k], ind)
+		}
+	}
+
+	type pair struct{ a, b *IndividualRecord }
+	compared := make(map[pair]bool)
+	order := make(map[*IndividualRecord]int, len(g.Individual))
+	for n, ind := range g.Individual {
+		order[ind] = n
+	}
+
+	var candidates []*DuplicateCandidate
+	for _, block := range blocks {
+		for i, a := range block {
+			for _, b := range block[i+1:] {
+				if order[b] < order[a] {
+					a, b = b, a
+				}
+				if compared[pair{a, b}] {
+					continue
+				}
+				compared[pair{a, b}] = true
+
+				if c := CompareIndividuals(a, b); c != nil && c.Score >= minScore {
+					candidates = append(candidates, c)
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if order[candidates[i].A] != order[candidates[j].A] {
+			return order[candidates[i].A] < order[candidates[j].A]
+		}
+		return order[candidates[i].B] < order[candidates[j].B]
+	})
+
+	return candidates
+}
+
+// CompareIndividuals scores how likely a and b are to be the same person by
+// their names, birth and death dates and places, parents and spouses. It
+// returns nil if they cannot be, because they are the same record or are of
+// different sexes.
+func CompareIndividuals(a *IndividualRecord, b *IndividualRecord) *DuplicateCandidate {
+	if a == b {
+		return nil
+	}
+	sa, sb := strings.ToUpper(a.Sex), strings.ToUpper(b.Sex)
+	if (sa == "M" || sa == "F") && (sb == "M" || sb == "F") && sa != sb {
+		return nil
+	}
+
+	c := &DuplicateCandidate{A: a, B: b}
+	add := func(points int, format string, args ...interface{}) {
+		c.Score += points
+		c.Reason = append(c.Reason, &DuplicateReason{Points: points, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	compareNames(a, b, add)
+
+	for _, tag := range []string{"BIRT", "DEAT"} {
+		ea, eb := a.firstEvent(tag), b.firstEvent(tag)
+		if ea == nil || eb == nil {
+			continue
+		}
+		compareEventDates(ea, eb, add)
+		comparePlaces(ea, eb, add)
+	}
+
+	compareRelatives("father", []*IndividualRecord{a.Father()}, []*IndividualRecord{b.Father()}, add)
+	compareRelatives("mother", []*IndividualRecord{a.Mother()}, []*IndividualRecord{b.Mother()}, add)
+	compareRelatives("spouse", a.Spouses(), b.Spouses(), add)
+
+	sort.SliceStable(c.Reason, func(i, j int) bool {
+		return c.Reason[i].Points > c.Reason[j].Points
+	})
+	return c
+}
+
+// compareNames scores the best matching surnames and given names of a and b.
+func compareNames(a *IndividualRecord, b *IndividualRecord, add func(int, string, ...interface{})) {
+	bestSurname, bestGiven := namePoints{points: -1000}, namePoints{points: -1000}
+	for _, na := range a.Name {
+		for _, nb := range b.Name {
+			if p := scoreName(na.Surname(), nb.Surname(), false); p.points > bestSurname.points {
+				bestSurname = p
+			}
+			if p := scoreName(firstWord(na.Given()), firstWord(nb.Given()), true); p.points > bestGiven.points {
+				bestGiven = p
+			}
+		}
+	}
+	if bestSurname.reason != "" {
+		add(bestSurname.points, "surname %s", bestSurname.reason)
+	}
+	if bestGiven.reason != "" {
+		add(bestGiven.points, "given name %s", bestGiven.reason)
+	}
+}
+
+type namePoints struct {
+	points int
+	reason string
+}
+
+// scoreName compares two names, allowing initials to match the given names
+// they abbreviate.
+func scoreName(x string, y string, initials bool) namePoints {
+	fx, fy := foldName(x), foldName(y)
+	switch {
+	case fx == "" || fy == "":
+		return namePoints{}
+	case fx == fy:
+		return namePoints{pointsNameExact, fmt.Sprintf("%s matches", x)}
+	case Soundex(fx) != "" && Soundex(fx) == Soundex(fy) || similarity(fx, fy) >= 0.8:
+		return namePoints{pointsNameSimilar, fmt.Sprintf("%s is similar to %s", x, y)}
+	case initials && (len([]rune(fx)) == 1 || len([]rune(fy)) == 1) && []rune(fx)[0] == []rune(fy)[0]:
+		return namePoints{pointsNameInitial, fmt.Sprintf("%s has the initial of %s", x, y)}
+	}
+	return namePoints{pointsNameDiffer, fmt.Sprintf("%s differs from %s", x, y)}
+}
+
+// compareEventDates scores the overlap of the dates of two events.
+func compareEventDates(ea *EventRecord, eb *EventRecord, add func(int, string, ...interface{})) {
+	ra, oka := dateRange(ea)
+	rb, okb := dateRange(eb)
+	if !oka || !okb {
+		return
+	}
+	what := eventWord(ea.Tag)
+	switch {
+	case !ra.Overlaps(rb):
+		add(pointsDateConflict, "%s dates %s and %s conflict", what, ea.Date, eb.Date)
+	case strings.EqualFold(ea.Date, eb.Date) && !ra.IsOpen() && ra.Latest.Sub(ra.Earliest) < 32*24*time.Hour:
+		add(pointsDateExact, "%s dates match (%s)", what, ea.Date)
+	default:
+		add(pointsDateOverlap, "%s dates %s and %s overlap", what, ea.Date, eb.Date)
+	}
+}
+
+// comparePlaces scores the places of two events.
+func comparePlaces(ea *EventRecord, eb *EventRecord, add func(int, string, ...interface{})) {
+	pa, pb := splitPlace(ea.Place.Name), splitPlace(eb.Place.Name)
+	if len(pa) == 0 || len(pb) == 0 {
+		return
+	}
+	what := eventWord(ea.Tag)
+	if foldName(strings.Join(pa, " ")) == foldName(strings.Join(pb, " ")) {
+		add(pointsPlaceExact, "%s places match (%s)", what, ea.Place.Name)
+		return
+	}
+	if pa[0] != "" && foldName(pa[0]) == foldName(pb[0]) {
+		add(pointsPlacePart, "%s places are both in %s", what, pa[0])
+	}
+}
+
+// compareRelatives scores a and b having the same relatives, either the same
+// records or people with similar names.
+func compareRelatives(role string, ra []*IndividualRecord, rb []*IndividualRecord, add func(int, string, ...interface{})) {
+	for _, x := range ra {
+		if x != nil && onPath(rb, x) {
+			add(pointsSameRelative, "same %s %s", role, x.Xref)
+			return
+		}
+	}
+	for _, x := range ra {
+		for _, y := range rb {
+			if x == nil || y == nil || len(x.Name) == 0 || len(y.Name) == 0 {
+				continue
+			}
+			if foldName(x.Name[0].Surname()) == foldName(y.Name[0].Surname()) &&
+				scoreName(firstWord(x.Name[0].Given()), firstWord(y.Name[0].Given()), false).points > 0 {
+				add(pointsSimilarName, "%ss %s and %s have similar names", role, x.Xref, y.Xref)
+				return
+			}
+		}
+	}
+}
+
+// firstEvent returns the individual's first event with tag.
+func (i *IndividualRecord) firstEvent(tag string) *EventRecord {
+	for _, e := range i.Event {
+		if e.Tag == tag {
+			return e
+		}
+	}
+	return nil
+}
+
+func eventWord(tag string) string {
+	switch tag {
+	case "BIRT":
+		return "birth"
+	case "DEAT":
+		return "death"
+	}
+	return tag
+}
+
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
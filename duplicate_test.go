@@ -0,0 +1,90 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameParts(t *testing.T) {
+	names := []*NameRecord{
+		{Name: "John Fitzgerald /Kennedy/ Jr"},
+		{Name: "/van der Berg/"},
+		{Name: "Mary Ann"},
+	}
+
+	stringTestCases{
+		{"Given", "John Fitzgerald", names[0].Given()},
+		{"Surname", "Kennedy", names[0].Surname()},
+		{"Given without given names", "", names[1].Given()},
+		{"Surname with spaces", "van der Berg", names[1].Surname()},
+		{"Given without surname", "Mary Ann", names[2].Given()},
+		{"Surname without surname", "", names[2].Surname()},
+	}.run(t)
+}
+
+func TestSoundex(t *testing.T) {
+	stringTestCases{
+		{"Soundex of Robert", "R163", Soundex("Robert")},
+		{"Soundex of Rupert", "R163", Soundex("Rupert")},
+		{"Soundex of Rubin", "R150", Soundex("Rubin")},
+		{"Soundex of Ashcraft", "A261", Soundex("Ashcraft")},
+		{"Soundex of Tymczak", "T522", Soundex("Tymczak")},
+		{"Soundex of Pfister", "P236", Soundex("Pfister")},
+		{"Soundex of Lee", "L000", Soundex("Lee")},
+		{"Soundex of O'Hara", "O600", Soundex("O'Hara")},
+		{"Soundex of empty name", "", Soundex("")},
+	}.run(t)
+
+	intTestCases{
+		{"Levenshtein distance from kitten to sitting was [%d]", 3, levenshtein("kitten", "sitting")},
+	}.run(t)
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dg := decodeFile(t, "testdata/duplicates.ged")
+
+	var pairs []string
+	for _, c := range FindDuplicates(dg, 0) {
+		pairs = append(pairs, c.A.Xref+"/"+c.B.Xref)
+	}
+	stringTestCases{
+		{"Duplicates", "I1/I6 I1/I2 I2/I6", strings.Join(pairs, " ")},
+	}.run(t)
+
+	best := CompareIndividuals(person(t, dg, "I1"), person(t, dg, "I2"))
+	var reasons []string
+	for _, r := range best.Reason {
+		reasons = append(reasons, r.String())
+	}
+	expected := []string{
+		"+25 same father I5",
+		"+20 surname Smith is similar to Smyth",
+		"+20 given name John is similar to Jon",
+		"+10 birth dates 12 MAR 1850 and MAR 1850 overlap",
+		"+5 birth places are both in Springfield",
+	}
+	stringTestCases{
+		{"Reasons", strings.Join(expected, "\n"), strings.Join(reasons, "\n")},
+	}.run(t)
+
+	intTestCases{
+		{"Score of I1 and I2 was [%d]", 80, best.Score},
+		{"Score of I1 and I3 was [%d]", 30, CompareIndividuals(person(t, dg, "I1"), person(t, dg, "I3")).Score},
+	}.run(t)
+
+	boolTestCases{
+		{"Comparison of man and woman", true, CompareIndividuals(person(t, dg, "I1"), person(t, dg, "I4")) == nil},
+	}.run(t)
+}
+
+func TestScoreNameWithoutLatinLetters(t *testing.T) {
+	intTestCases{
+		{"Points for Петров and Смирнов were [%d]", pointsNameDiffer, scoreName("Петров", "Смирнов", false).points},
+		{"Points for Παπαδόπουλος and Νικολάου were [%d]", pointsNameDiffer, scoreName("Παπαδόπουλος", "Νικολάου", false).points},
+		{"Points for Петров and Петрова were [%d]", pointsNameSimilar, scoreName("Петров", "Петрова", false).points},
+	}.run(t)
+}
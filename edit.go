@@ -0,0 +1,320 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The editing methods below keep both sides of every link between
+// individuals and families in step: a family's HUSB, WIFE and CHIL and the
+// FAMS and FAMC of its members. New records are given unused xrefs, and the
+// change date of every record added or changed is set to now.
+
+// AddIndividual adds an individual with the given name, such as
+// "John /Smith/", and sex to g. Either may be empty.
+func (g *Gedcom) AddIndividual(name string, sex string) *IndividualRecord {
+	i := &IndividualRecord{Xref: g.newXref("I"), Sex: sex, Changed: newChangedRecord(nil)}
+	if name != "" {
+		i.Name = []*NameRecord{{Name: name}}
+	}
+	g.Individual = append(g.Individual, i)
+	return i
+}
+
+// AddFamily adds a family to g with the given spouses, either of which may
+// be nil.
+func (g *Gedcom) AddFamily(husband *IndividualRecord, wife *IndividualRecord) (*FamilyRecord, error) {
+	for _, i := range []*IndividualRecord{husband, wife} {
+		if i != nil && !g.hasIndividual(i) {
+			return nil, fmt.Errorf("individual %s is not in the file", i.Xref)
+		}
+	}
+	if husband != nil && husband == wife {
+		return nil, fmt.Errorf("individual %s cannot be both spouses", husband.Xref)
+	}
+
+	f := &FamilyRecord{Xref: g.newXref("F"), Changed: newChangedRecord(nil)}
+	g.Family = append(g.Family, f)
+	if husband != nil {
+		linkSpouse(f, &f.Husband, husband)
+	}
+	if wife != nil {
+		linkSpouse(f, &f.Wife, wife)
+	}
+	return f, nil
+}
+
+// SetSpouse makes ind the husband of fam if ind is male, the wife if female,
+// or, if ind's sex is not known, whichever the family lacks. Any spouse ind
+// replaces is removed from the family.
+func (g *Gedcom) SetSpouse(fam *FamilyRecord, ind *IndividualRecord) error {
+	if err := g.checkEdit(fam, ind); err != nil {
+		return err
+	}
+	if fam.childRecord(ind) != nil {
+		return fmt.Errorf("individual %s is a child of family %s", ind.Xref, fam.Xref)
+	}
+	if fam.Husband == ind || fam.Wife == ind {
+		return nil
+	}
+
+	var place **IndividualRecord
+	switch strings.ToUpper(ind.Sex) {
+	case "M":
+		place = &fam.Husband
+	case "F":
+		place = &fam.Wife
+	default:
+		switch {
+		case fam.Husband == nil:
+			place = &fam.Husband
+		case fam.Wife == nil:
+			place = &fam.Wife
+		default:
+			return fmt.Errorf("family %s already has two spouses", fam.Xref)
+		}
+	}
+	if *place != nil {
+		unlinkSpouse(fam, *place)
+	}
+	linkSpouse(fam, place, ind)
+	return nil
+}
+
+// RemoveSpouse removes ind as a spouse of fam.
+func (g *Gedcom) RemoveSpouse(fam *FamilyRecord, ind *IndividualRecord) error {
+	if err := g.checkEdit(fam, ind); err != nil {
+		return err
+	}
+	if fam.Husband != ind && fam.Wife != ind {
+		return fmt.Errorf("individual %s is not a spouse in family %s", ind.Xref, fam.Xref)
+	}
+	unlinkSpouse(fam, ind)
+	return nil
+}
+
+// LinkChild adds ind to the children of fam, with a pedigree such as "birth"
+// or "adopted", which may be empty.
+func (g *Gedcom) LinkChild(fam *FamilyRecord, ind *IndividualRecord, pedigree string) error {
+	if err := g.checkEdit(fam, ind); err != nil {
+		return err
+	}
+	if fam.Husband == ind || fam.Wife == ind {
+		return fmt.Errorf("individual %s is a spouse in family %s", ind.Xref, fam.Xref)
+	}
+	if fam.childRecord(ind) != nil {
+		return fmt.Errorf("individual %s is already a child of family %s", ind.Xref, fam.Xref)
+	}
+
+	fam.Child = append(fam.Child, &ChildRecord{Person: ind})
+	ind.Parents = append(ind.Parents, &FamilyLinkRecord{Family: fam, Pedigree: pedigree})
+	fam.Changed = newChangedRecord(fam.Changed)
+	ind.Changed = newChangedRecord(ind.Changed)
+	return nil
+}
+
+// UnlinkChild removes ind from the children of fam.
+func (g *Gedcom) UnlinkChild(fam *FamilyRecord, ind *IndividualRecord) error {
+	if err := g.checkEdit(fam, ind); err != nil {
+		return err
+	}
+	if fam.childRecord(ind) == nil {
+		return fmt.Errorf("individual %s is not a child of family %s", ind.Xref, fam.Xref)
+	}
+	unlinkChild(fam, ind)
+	return nil
+}
+
+// RemoveIndividual removes ind from g, from every family in which they are a
+// spouse or child and from every association with them. Families left
+// without members are kept.
+func (g *Gedcom) RemoveIndividual(ind *IndividualRecord) error {
+	if ind == nil || !g.hasIndividual(ind) {
+		return fmt.Errorf("individual is not in the file")
+	}
+
+	for _, f := range g.Family {
+		if f.Husband == ind || f.Wife == ind {
+			unlinkSpouse(f, ind)
+		}
+		if f.childRecord(ind) != nil {
+			unlinkChild(f, ind)
+		}
+	}
+
+	individuals := make([]*IndividualRecord, 0, len(g.Individual)-1)
+	for _, i := range g.Individual {
+		if i == ind {
+			continue
+		}
+		individuals = append(individuals, i)
+		if removeAssociations(i, ind) {
+			i.Changed = newChangedRecord(i.Changed)
+		}
+	}
+	g.Individual = individuals
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return nil
+}
+
+// AddEvent adds an event or attribute with tag, such as "BIRT" or "OCCU", and
+// the given date and place, either of which may be empty, to rec, an
+// individual or family. Events are kept in date order.
+func (g *Gedcom) AddEvent(rec interface{}, tag string, date string, place string) (*EventRecord, error) {
+	e := &EventRecord{Tag: tag, Date: date, Place: PlaceRecord{Name: place}}
+	if date != "" {
+		e.SortDate = getSortDate(date)
+	} else {
+		e.SortDate = time.Now()
+	}
+
+	switch r := rec.(type) {
+	case *IndividualRecord:
+		if r == nil || !g.hasIndividual(r) {
+			return nil, fmt.Errorf("individual is not in the file")
+		}
+		if attributeTags[tag] {
+			r.Attribute = insertEvent(r.Attribute, e)
+		} else {
+			r.Event = insertEvent(r.Event, e)
+		}
+		r.Changed = newChangedRecord(r.Changed)
+	case *FamilyRecord:
+		if r == nil || !g.hasFamily(r) {
+			return nil, fmt.Errorf("family is not in the file")
+		}
+		r.Event = insertEvent(r.Event, e)
+		r.Changed = newChangedRecord(r.Changed)
+	default:
+		return nil, fmt.Errorf("cannot add an event to %T", rec)
+	}
+
+	if place != "" {
+		g.Place = indexPlaces(g)
+	}
+	return e, nil
+}
+
+// insertEvent adds e to events before the first dated event later than e,
+// leaving the others in their order.
+func insertEvent(events []*EventRecord, e *EventRecord) []*EventRecord {
+	i := len(events)
+	for j, x := range events {
+		if !x.SortDate.IsZero() && x.SortDate.After(e.SortDate) {
+			i = j
+			break
+		}
+	}
+	events = append(events, nil)
+	copy(events[i+1:], events[i:])
+	events[i] = e
+	return events
+}
+
+// linkSpouse puts ind in the spouse field place of fam and links ind to fam.
+func linkSpouse(fam *FamilyRecord, place **IndividualRecord, ind *IndividualRecord) {
+	*place = ind
+	ind.Family = append(ind.Family, &FamilyLinkRecord{Family: fam})
+	fam.Changed = newChangedRecord(fam.Changed)
+	ind.Changed = newChangedRecord(ind.Changed)
+}
+
+// unlinkSpouse removes ind as a spouse of fam and fam from ind's families.
+func unlinkSpouse(fam *FamilyRecord, ind *IndividualRecord) {
+	if fam.Husband == ind {
+		fam.Husband = nil
+	}
+	if fam.Wife == ind {
+		fam.Wife = nil
+	}
+	ind.Family = withoutFamily(ind.Family, fam)
+	fam.Changed = newChangedRecord(fam.Changed)
+	ind.Changed = newChangedRecord(ind.Changed)
+}
+
+// unlinkChild removes ind from the children of fam and fam from ind's
+// parent families.
+func unlinkChild(fam *FamilyRecord, ind *IndividualRecord) {
+	var children []*ChildRecord
+	for _, c := range fam.Child {
+		if c.Person != ind {
+			children = append(children, c)
+		}
+	}
+	fam.Child = children
+	ind.Parents = withoutFamily(ind.Parents, fam)
+	fam.Changed = newChangedRecord(fam.Changed)
+	ind.Changed = newChangedRecord(ind.Changed)
+}
+
+// withoutFamily returns links without those to fam.
+func withoutFamily(links []*FamilyLinkRecord, fam *FamilyRecord) []*FamilyLinkRecord {
+	var kept []*FamilyLinkRecord
+	for _, l := range links {
+		if l.Family != fam {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// removeAssociations removes the associations of i and its events with ind
+// and reports whether there were any.
+func removeAssociations(i *IndividualRecord, ind *IndividualRecord) bool {
+	removed := false
+	without := func(assocs []*AssociationRecord) []*AssociationRecord {
+		var kept []*AssociationRecord
+		for _, a := range assocs {
+			if a.Person == ind {
+				removed = true
+				continue
+			}
+			kept = append(kept, a)
+		}
+		return kept
+	}
+
+	i.Association = without(i.Association)
+	for _, events := range [][]*EventRecord{i.Event, i.Attribute} {
+		for _, e := range events {
+			e.Association = without(e.Association)
+		}
+	}
+	return removed
+}
+
+// checkEdit checks that fam and ind are records of g.
+func (g *Gedcom) checkEdit(fam *FamilyRecord, ind *IndividualRecord) error {
+	if fam == nil || !g.hasFamily(fam) {
+		return fmt.Errorf("family is not in the file")
+	}
+	if ind == nil || !g.hasIndividual(ind) {
+		return fmt.Errorf("individual is not in the file")
+	}
+	return nil
+}
+
+func (g *Gedcom) hasIndividual(ind *IndividualRecord) bool {
+	for _, i := range g.Individual {
+		if i == ind {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Gedcom) hasFamily(fam *FamilyRecord) bool {
+	for _, f := range g.Family {
+		if f == fam {
+			return true
+		}
+	}
+	return false
+}
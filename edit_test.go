@@ -0,0 +1,157 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditFamilies(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC) }
+
+	eg := decodeFile(t, "testdata/ours.ged")
+	f1, i3 := family(t, eg, "F1"), person(t, eg, "I3")
+
+	jane := eg.AddIndividual("Jane /Grant/", "F")
+	if err := eg.LinkChild(f1, jane, "adopted"); err != nil {
+		t.Fatalf("LinkChild gave error %v, expected no error", err)
+	}
+
+	ann := eg.AddIndividual("Ann /Hill/", "F")
+	fam, err := eg.AddFamily(i3, nil)
+	if err != nil {
+		t.Fatalf("AddFamily gave error %v, expected no error", err)
+	}
+	if err := eg.SetSpouse(fam, ann); err != nil {
+		t.Fatalf("SetSpouse gave error %v, expected no error", err)
+	}
+	if _, err := eg.AddEvent(fam, "MARR", "1858", "Kirkby"); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+	if _, err := eg.AddEvent(jane, "OCCU", "", ""); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+	if _, err := eg.AddEvent(i3, "CHR", "1830", ""); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"New individual", "I4", jane.Xref},
+		{"Second new individual", "I5", ann.Xref},
+		{"New family", "F2", fam.Xref},
+		{"F1 children", "I3 I4", xrefs(f1.Children())},
+		{"Jane's adoptive family", "F1", jane.ParentFamilies("adopted")[0].Xref},
+		{"I3 spouses", "I5", xrefs(i3.Spouses())},
+		{"Ann's spouses", "I3", xrefs(ann.Spouses())},
+		{"Marriage place", "Kirkby", fam.Event[0].Place.Place.Name},
+		{"Jane's occupation", "OCCU", jane.Attribute[0].Tag},
+		{"I3 events", "BIRT", i3.Event[0].Tag},
+		{"F1 change date", "5 MAR 2024", f1.Changed.Stamp.Date},
+		{"Jane change date", "5 MAR 2024", jane.Changed.Stamp.Date},
+	}.run(t)
+
+	intTestCases{
+		{"I3 event count was [%d]", 2, len(i3.Event)},
+	}.run(t)
+
+	errorCases := []struct {
+		tested string
+		err    error
+	}{
+		{"Linking a spouse as a child", eg.LinkChild(fam, ann, "")},
+		{"Linking a child twice", eg.LinkChild(f1, jane, "")},
+		{"Making a child a spouse", eg.SetSpouse(f1, jane)},
+		{"Linking a stranger", eg.LinkChild(f1, &IndividualRecord{Xref: "X1"}, "")},
+	}
+	for _, ec := range errorCases {
+		if ec.err == nil {
+			t.Fatalf("%s gave no error, expected an error", ec.tested)
+		}
+	}
+}
+
+func TestRemoveIndividualPlaces(t *testing.T) {
+	eg := decodeFile(t, "testdata/places.ged")
+	if err := eg.RemoveIndividual(person(t, eg, "I2")); err != nil {
+		t.Fatalf("RemoveIndividual gave error %v, expected no error", err)
+	}
+
+	var names []string
+	for _, p := range eg.Place {
+		names = append(names, p.Name)
+	}
+	stringTestCases{
+		{"Places", "Salem, Essex, Massachusetts, USA; Essex, Massachusetts, USA", strings.Join(names, "; ")},
+	}.run(t)
+}
+
+func TestAddEventKeepsOrder(t *testing.T) {
+	eg := decodeFile(t, "testdata/ours.ged")
+	i1 := person(t, eg, "I1")
+	i1.Event = []*EventRecord{i1.Event[0], {Tag: "EVEN", Type: "Undated"}, i1.Event[1]}
+
+	if _, err := eg.AddEvent(i1, "EMIG", "1840", ""); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+	if _, err := eg.AddEvent(i1, "CENS", "", ""); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+
+	var tags []string
+	for _, e := range i1.Event {
+		tags = append(tags, e.Tag)
+	}
+	stringTestCases{
+		{"I1 events", "BIRT EVEN EMIG DEAT CENS", strings.Join(tags, " ")},
+	}.run(t)
+}
+
+func TestSetSpouseReplaces(t *testing.T) {
+	eg := decodeFile(t, "testdata/ours.ged")
+	f1, i1 := family(t, eg, "F1"), person(t, eg, "I1")
+
+	bill := eg.AddIndividual("Bill /Grant/", "M")
+	if err := eg.SetSpouse(f1, bill); err != nil {
+		t.Fatalf("SetSpouse gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"F1 husband", "I4", f1.Husband.Xref},
+		{"Bill's spouses", "I2", xrefs(bill.Spouses())},
+		{"Mary's spouses", "I4", xrefs(person(t, eg, "I2").Spouses())},
+		{"I1 spouses", "", xrefs(i1.Spouses())},
+	}.run(t)
+}
+
+func TestRemoveIndividual(t *testing.T) {
+	eg := decodeFile(t, "testdata/ours.ged")
+	f1, i1, i3 := family(t, eg, "F1"), person(t, eg, "I1"), person(t, eg, "I3")
+
+	if err := eg.RemoveIndividual(i3); err != nil {
+		t.Fatalf("RemoveIndividual gave error %v, expected no error", err)
+	}
+	if err := eg.RemoveIndividual(i1); err != nil {
+		t.Fatalf("RemoveIndividual gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Individual count after removal was [%d]", 1, len(eg.Individual)},
+		{"F1 child count after removal was [%d]", 0, len(f1.Child)},
+		{"Family count after removal was [%d]", 1, len(eg.Family)},
+	}.run(t)
+
+	boolTestCases{
+		{"F1 has a husband", false, f1.Husband != nil},
+		{"F1 has a wife", true, f1.Wife != nil},
+		{"Removing twice succeeded", false, eg.RemoveIndividual(i1) == nil},
+	}.run(t)
+
+	intTestCases{
+		{"Citations of S1 after removal was [%d]", 0, len(eg.CitationsOf(eg.Source[0]))},
+	}.run(t)
+}
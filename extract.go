@@ -0,0 +1,254 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ExtractOptions controls which relatives Extract includes.
+type ExtractOptions struct {
+	Ancestors     int  // generations of ancestors to include; negative for all
+	Descendants   int  // generations of descendants to include; negative for all
+	Spouses       bool // include the spouses of the root person and their descendants
+	SpouseParents bool // include the parents of those spouses too
+}
+
+// Extract returns a new Gedcom holding the root person and those of their
+// relatives chosen by opts, with the families that link two or more of
+// them, and the sources, notes, objects, repositories and submitters those
+// records refer to. Links to anyone or any family not included are removed.
+// The header and submission are those of g. A nil opts includes only the
+// root person.
+func Extract(g *Gedcom, root *IndividualRecord, opts *ExtractOptions) (*Gedcom, error) {
+	if root == nil || !g.hasIndividual(root) {
+		return nil, fmt.Errorf("root individual is not in the file")
+	}
+	if opts == nil {
+		opts = &ExtractOptions{}
+	}
+
+	people := branch(root, opts)
+
+	c := g.Copy()
+	keep := make(map[interface{}]bool)
+	var individuals []*IndividualRecord
+	for n, i := range g.Individual {
+		if people[i] {
+			keep[c.Individual[n]] = true
+			individuals = append(individuals, c.Individual[n])
+		}
+	}
+
+	var families []*FamilyRecord
+	for _, f := range c.Family {
+		members := 0
+		for _, i := range append([]*IndividualRecord{f.Husband, f.Wife}, f.Children()...) {
+			if i != nil && keep[i] {
+				members++
+			}
+		}
+		if members >= 2 {
+			keep[f] = true
+			families = append(families, f)
+		}
+	}
+
+	seen := make(map[interface{}]bool)
+	for _, i := range individuals {
+		pruneLinks(reflect.ValueOf(i).Elem(), keep, seen)
+	}
+	for _, f := range families {
+		pruneLinks(reflect.ValueOf(f).Elem(), keep, seen)
+	}
+
+	return subset(c, individuals, families), nil
+}
+
+// subset returns a Gedcom holding individuals and families, records of c,
+// and the other records of c they refer to, with the header, submission and
+// trailer of c.
+func subset(c *Gedcom, individuals []*IndividualRecord, families []*FamilyRecord) *Gedcom {
+	eg := &Gedcom{
+		Header:     c.Header,
+		Submission: c.Submission,
+		Individual: individuals,
+		Family:     families,
+		Trailer:    c.Trailer,
+	}
+
+	reached := make(map[interface{}]bool)
+	eg.walk(func(rec interface{}, owner interface{}) {
+		reached[rec] = true
+	})
+	pointers := make(map[string]bool)
+	for _, s := range c.Source {
+		if reached[s] {
+			for _, p := range append(append([]string(nil), s.Repository...), s.Submitter...) {
+				pointers[stripXref(p)] = true
+			}
+		}
+	}
+	for _, s := range c.Source {
+		if reached[s] {
+			eg.Source = append(eg.Source, s)
+		}
+	}
+	for _, n := range c.Note {
+		if reached[n] {
+			eg.Note = append(eg.Note, n)
+		}
+	}
+	for _, o := range c.Object {
+		if reached[o] {
+			eg.Object = append(eg.Object, o)
+		}
+	}
+	for _, r := range c.Repository {
+		if pointers[r.Xref] {
+			eg.Repository = append(eg.Repository, r)
+		}
+	}
+	for _, u := range c.Submitter {
+		if reached[u] || pointers[u.Xref] {
+			eg.Submitter = append(eg.Submitter, u)
+		}
+	}
+
+	eg.Place = indexPlaces(eg)
+	return eg
+}
+
+// branch returns the people chosen by opts from around root.
+func branch(root *IndividualRecord, opts *ExtractOptions) map[*IndividualRecord]bool {
+	people := map[*IndividualRecord]bool{root: true}
+	walkOpts := &TraversalOptions{Distinct: true}
+
+	if opts.Ancestors != 0 {
+		WalkAncestors(root, generations(opts.Ancestors), walkOpts, func(step *LineageStep) bool {
+			people[step.Person] = true
+			return true
+		})
+	}
+
+	line := []*IndividualRecord{root}
+	if opts.Descendants != 0 {
+		WalkDescendants(root, generations(opts.Descendants), walkOpts, func(step *LineageStep) bool {
+			people[step.Person] = true
+			line = appendPerson(line, step.Person)
+			return true
+		})
+	}
+
+	if opts.Spouses || opts.SpouseParents {
+		for _, i := range line {
+			for _, s := range i.Spouses() {
+				if people[s] {
+					continue
+				}
+				people[s] = true
+				if !opts.SpouseParents {
+					continue
+				}
+				for _, f := range s.ParentFamilies() {
+					for _, p := range []*IndividualRecord{f.Husband, f.Wife} {
+						if p != nil {
+							people[p] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return people
+}
+
+// generations converts a number of generations to extract to the maximum
+// used by WalkAncestors and WalkDescendants, for which zero means no limit.
+func generations(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// pruneLinks removes from the structure s, and the structures it holds,
+// every reference to an individual or family not in keep. List elements
+// that link to one, such as children and family links, are removed whole.
+func pruneLinks(s reflect.Value, keep map[interface{}]bool, seen map[interface{}]bool) {
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Ptr:
+			switch {
+			case f.IsNil() || f.Elem().Kind() != reflect.Struct || f.Elem().Type().PkgPath() != pkgPath:
+			case f.Type() == reflect.TypeOf(&Place{}):
+				// the place index is rebuilt rather than pruned
+			case isLinkable(f.Type()):
+				if !keep[f.Interface()] {
+					f.Set(reflect.Zero(f.Type()))
+				}
+			case recordXref(f) == "" && !seen[f.Interface()]:
+				seen[f.Interface()] = true
+				pruneLinks(f.Elem(), keep, seen)
+			}
+		case reflect.Struct:
+			if f.Type().PkgPath() == pkgPath {
+				pruneLinks(f, keep, seen)
+			}
+		case reflect.Slice:
+			if f.Type().Elem().Kind() != reflect.Ptr {
+				continue
+			}
+			kept := reflect.MakeSlice(f.Type(), 0, f.Len())
+			for j := 0; j < f.Len(); j++ {
+				if e := f.Index(j); !linksElsewhere(e, keep) {
+					kept = reflect.Append(kept, e)
+				}
+			}
+			if kept.Len() < f.Len() {
+				f.Set(kept)
+			}
+			for j := 0; j < f.Len(); j++ {
+				e := f.Index(j)
+				if !e.IsNil() && e.Elem().Kind() == reflect.Struct && recordXref(e) == "" && !seen[e.Interface()] {
+					seen[e.Interface()] = true
+					pruneLinks(e.Elem(), keep, seen)
+				}
+			}
+		}
+	}
+}
+
+// isLinkable reports whether t is a pointer to an individual or family.
+func isLinkable(t reflect.Type) bool {
+	return t == reflect.TypeOf(&IndividualRecord{}) || t == reflect.TypeOf(&FamilyRecord{})
+}
+
+// linksElsewhere reports whether the list element e is, or directly refers
+// to, an individual or family not in keep.
+func linksElsewhere(e reflect.Value, keep map[interface{}]bool) bool {
+	if e.IsNil() {
+		return false
+	}
+	if isLinkable(e.Type()) {
+		return !keep[e.Interface()]
+	}
+	if e.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	s := e.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if f := s.Field(i); f.CanInterface() && isLinkable(f.Type()) && !f.IsNil() && !keep[f.Interface()] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	pg := decodeFile(t, "testdata/pedigree.ged")
+
+	eg, err := Extract(pg, person(t, pg, "I7"), &ExtractOptions{Ancestors: 1, Descendants: -1, Spouses: true, SpouseParents: true})
+	if err != nil {
+		t.Fatalf("Extract gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Individuals", "I3 I4 I5 I6 I7 I8 I9 I10", xrefs(eg.Individual)},
+		{"Families", "F1 F2 F3 F4", familyXrefs(eg.Family)},
+		{"I3 parent families", "F1", familyXrefs(person(t, eg, "I3").ParentFamilies())},
+		{"F1 children", "I3 I4", xrefs(family(t, eg, "F1").Children())},
+		{"I9 grandparents", "I3 I5", xrefs([]*IndividualRecord{person(t, eg, "I9").Father().Father(), person(t, eg, "I9").Father().Mother()})},
+	}.run(t)
+
+	boolTestCases{
+		{"F1 has a husband", false, family(t, eg, "F1").Husband != nil},
+		{"Original F1 has a husband", true, family(t, pg, "F1").Husband != nil},
+		{"Extracted I3 is the original", false, person(t, eg, "I3") == person(t, pg, "I3")},
+	}.run(t)
+
+	intTestCases{
+		{"Original individual count was [%d]", 15, len(pg.Individual)},
+		{"I4 family link count was [%d]", 1, len(person(t, eg, "I4").Family)},
+	}.run(t)
+}
+
+func TestExtractDescendantsOnly(t *testing.T) {
+	pg := decodeFile(t, "testdata/pedigree.ged")
+
+	eg, err := Extract(pg, person(t, pg, "I7"), &ExtractOptions{Descendants: 1})
+	if err != nil {
+		t.Fatalf("Extract gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Individuals", "I7 I9 I10", xrefs(eg.Individual)},
+		{"Families", "F4", familyXrefs(eg.Family)},
+		{"I7 parent families", "", familyXrefs(person(t, eg, "I7").ParentFamilies())},
+	}.run(t)
+
+	boolTestCases{
+		{"F4 has a wife", false, family(t, eg, "F4").Wife != nil},
+	}.run(t)
+}
+
+func TestExtractRecords(t *testing.T) {
+	og := decodeFile(t, "testdata/ours.ged")
+
+	eg, err := Extract(og, person(t, og, "I3"), &ExtractOptions{Ancestors: 1})
+	if err != nil {
+		t.Fatalf("Extract gave error %v, expected no error", err)
+	}
+	intTestCases{
+		{"Source count was [%d]", 1, len(eg.Source)},
+		{"Note count was [%d]", 1, len(eg.Note)},
+		{"Repository count was [%d]", 1, len(eg.Repository)},
+		{"Submitter count was [%d]", 1, len(eg.Submitter)},
+	}.run(t)
+
+	eg, err = Extract(og, person(t, og, "I2"), nil)
+	if err != nil {
+		t.Fatalf("Extract gave error %v, expected no error", err)
+	}
+	intTestCases{
+		{"Individual count of one person was [%d]", 1, len(eg.Individual)},
+		{"Family count of one person was [%d]", 0, len(eg.Family)},
+		{"Family link count of one person was [%d]", 0, len(eg.Individual[0].Family)},
+		{"Source count of one person was [%d]", 0, len(eg.Source)},
+		{"Note count of one person was [%d]", 0, len(eg.Note)},
+		{"Repository count of one person was [%d]", 0, len(eg.Repository)},
+		{"Submitter count of one person was [%d]", 1, len(eg.Submitter)},
+	}.run(t)
+}
+
+func familyXrefs(families []*FamilyRecord) string {
+	var s []string
+	for _, f := range families {
+		s = append(s, f.Xref)
+	}
+	return strings.Join(s, " ")
+}
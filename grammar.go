@@ -0,0 +1,852 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rule allows a tag within a structure. A rule with an empty Tag instead
+// includes every rule of the named Structure, which is how shared parts of the
+// grammar such as EVENT_DETAIL are written once.
+type Rule struct {
+	Tag       string
+	Min       int
+	Max       int    // zero means any number
+	Structure string // the structure describing the tag's substructures, if any
+	Value     string // the type of the tag's value, if checked
+}
+
+// ValueType constrains the value of a line.
+type ValueType struct {
+	MaxLength int      // in characters; zero means unlimited
+	Enum      []string // the allowed values, if enumerated
+	List      bool     // the value is a comma separated list of Enum values
+	Pointer   bool     // the value must be a pointer such as @I1@
+}
+
+// Grammar describes the structures allowed by a version of GEDCOM. The
+// top level records are the rules of the "GEDCOM" structure.
+type Grammar struct {
+	Version       string
+	Structures    map[string][]Rule
+	Values        map[string]ValueType
+	MaxLineLength int  // zero means unlimited
+	CaseSensitive bool // whether enumerated values must match case
+}
+
+const many = 0
+
+// include returns a rule that splices in the rules of another structure.
+func include(structure string) Rule {
+	return Rule{Structure: structure}
+}
+
+// Grammar551 is the lineage-linked grammar of GEDCOM 5.5.1.
+var Grammar551 = &Grammar{
+	Version:       "5.5.1",
+	MaxLineLength: 255,
+	Structures: map[string][]Rule{
+		"GEDCOM": {
+			{"HEAD", 1, 1, "HEAD", ""},
+			{"SUBN", 0, 1, "SUBN", ""},
+			{"FAM", 0, many, "FAM", ""},
+			{"INDI", 0, many, "INDI", ""},
+			{"OBJE", 0, many, "OBJE", ""},
+			{"NOTE", 0, many, "NOTE", "text"},
+			{"REPO", 0, many, "REPO", ""},
+			{"SOUR", 0, many, "SOUR", ""},
+			{"SUBM", 0, many, "SUBM", ""},
+			{"TRLR", 1, 1, "", ""},
+		},
+
+		"HEAD": {
+			{"SOUR", 1, 1, "HEAD.SOUR", "systemID"},
+			{"DEST", 0, 1, "", "systemID"},
+			{"DATE", 0, 1, "HEAD.DATE", "date"},
+			{"SUBM", 1, 1, "", "pointer"},
+			{"SUBN", 0, 1, "", "pointer"},
+			{"FILE", 0, 1, "", "fileName"},
+			{"COPR", 0, 1, "", "copyright"},
+			{"GEDC", 1, 1, "HEAD.GEDC", ""},
+			{"CHAR", 1, 1, "HEAD.CHAR", "charset"},
+			{"LANG", 0, 1, "", "language"},
+			{"PLAC", 0, 1, "HEAD.PLAC", ""},
+			{"NOTE", 0, 1, "TEXT", ""},
+		},
+		"HEAD.SOUR": {
+			{"VERS", 0, 1, "", "version"},
+			{"NAME", 0, 1, "", "productName"},
+			{"CORP", 0, 1, "ADDRESS_STRUCTURE", "name"},
+			{"DATA", 0, 1, "HEAD.SOUR.DATA", "name"},
+		},
+		"HEAD.SOUR.DATA": {
+			{"DATE", 0, 1, "", "date"},
+			{"COPR", 0, 1, "TEXT", "copyright"},
+		},
+		"HEAD.DATE": {
+			{"TIME", 0, 1, "", "time"},
+		},
+		"HEAD.GEDC": {
+			{"VERS", 1, 1, "", "version"},
+			{"FORM", 1, 1, "", "gedcomForm"},
+		},
+		"HEAD.CHAR": {
+			{"VERS", 0, 1, "", "version"},
+		},
+		"HEAD.PLAC": {
+			{"FORM", 1, 1, "", "placeForm"},
+		},
+
+		"TEXT": {
+			{"CONC", 0, many, "", "text"},
+			{"CONT", 0, many, "", "text"},
+		},
+		"ADDRESS_STRUCTURE": {
+			{"ADDR", 0, 1, "ADDR", "addressLine"},
+			{"PHON", 0, 3, "", "phone"},
+			{"EMAIL", 0, 3, "", "email"},
+			{"FAX", 0, 3, "", "phone"},
+			{"WWW", 0, 3, "", "url"},
+		},
+		"ADDR": {
+			{"CONT", 0, 3, "", "addressLine"},
+			{"ADR1", 0, 1, "", "addressLine"},
+			{"ADR2", 0, 1, "", "addressLine"},
+			{"ADR3", 0, 1, "", "addressLine"},
+			{"CITY", 0, 1, "", "addressLine"},
+			{"STAE", 0, 1, "", "addressLine"},
+			{"POST", 0, 1, "", "postalCode"},
+			{"CTRY", 0, 1, "", "addressLine"},
+		},
+		"CHAN": {
+			{"DATE", 1, 1, "CHAN.DATE", "changeDate"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"CHAN.DATE": {
+			{"TIME", 0, 1, "", "time"},
+		},
+		"REFN": {
+			{"TYPE", 0, 1, "", "type"},
+		},
+		"RECORD": {
+			{"REFN", 0, many, "REFN", "refn"},
+			{"RIN", 0, 1, "", "rin"},
+			{"CHAN", 0, 1, "CHAN", ""},
+		},
+		"NOTE_STRUCTURE": {
+			include("TEXT"),
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+		"SOURCE_CITATION": {
+			include("TEXT"),
+			{"PAGE", 0, 1, "TEXT", "page"},
+			{"EVEN", 0, 1, "SOURCE_CITATION.EVEN", "eventType"},
+			{"DATA", 0, 1, "SOURCE_CITATION.DATA", ""},
+			{"QUAY", 0, 1, "", "quality"},
+			{"TEXT", 0, many, "TEXT", "text"},
+			{"OBJE", 0, many, "MULTIMEDIA_LINK", ""},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"SOURCE_CITATION.EVEN": {
+			{"ROLE", 0, 1, "", "role"},
+		},
+		"SOURCE_CITATION.DATA": {
+			{"DATE", 0, 1, "", "date"},
+			{"TEXT", 0, many, "TEXT", "text"},
+		},
+		"MULTIMEDIA_LINK": {
+			{"FILE", 0, many, "MULTIMEDIA_LINK.FILE", "fileName"},
+			{"TITL", 0, 1, "", "descriptiveTitle"},
+		},
+		"MULTIMEDIA_LINK.FILE": {
+			{"FORM", 1, 1, "MULTIMEDIA_LINK.FORM", "mediaFormat"},
+		},
+		"MULTIMEDIA_LINK.FORM": {
+			{"MEDI", 0, 1, "", "mediaType"},
+		},
+		"CITATIONS": {
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+			{"OBJE", 0, many, "MULTIMEDIA_LINK", ""},
+		},
+
+		"EVENT_DETAIL": {
+			{"TYPE", 0, 1, "", "type"},
+			{"DATE", 0, 1, "", "date"},
+			{"PLAC", 0, 1, "PLACE_STRUCTURE", "placeName"},
+			include("ADDRESS_STRUCTURE"),
+			{"AGNC", 0, 1, "", "agency"},
+			{"RELI", 0, 1, "", "religion"},
+			{"CAUS", 0, 1, "", "cause"},
+			{"RESN", 0, 1, "", "restriction"},
+			include("CITATIONS"),
+		},
+		"PLACE_STRUCTURE": {
+			{"FORM", 0, 1, "", "placeForm"},
+			{"FONE", 0, many, "PLACE_VARIANT", "placeName"},
+			{"ROMN", 0, many, "PLACE_VARIANT", "placeName"},
+			{"MAP", 0, 1, "MAP", ""},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"PLACE_VARIANT": {
+			{"TYPE", 1, 1, "", "type"},
+		},
+		"MAP": {
+			{"LATI", 1, 1, "", "latitude"},
+			{"LONG", 1, 1, "", "longitude"},
+		},
+		"INDIVIDUAL_EVENT": {
+			include("EVENT_DETAIL"),
+			{"AGE", 0, 1, "", "age"},
+		},
+		"BIRTH_EVENT": {
+			include("INDIVIDUAL_EVENT"),
+			{"FAMC", 0, 1, "", "pointer"},
+		},
+		"ADOPTION_EVENT": {
+			include("INDIVIDUAL_EVENT"),
+			{"FAMC", 0, 1, "ADOPTION_EVENT.FAMC", "pointer"},
+		},
+		"ADOPTION_EVENT.FAMC": {
+			{"ADOP", 0, 1, "", "adoptedBy"},
+		},
+		"FAMILY_EVENT": {
+			include("EVENT_DETAIL"),
+			{"HUSB", 0, 1, "SPOUSE_AGE", ""},
+			{"WIFE", 0, 1, "SPOUSE_AGE", ""},
+		},
+		"SPOUSE_AGE": {
+			{"AGE", 1, 1, "", "age"},
+		},
+		"LDS_ORDINANCE": {
+			{"DATE", 0, 1, "", "date"},
+			{"TEMP", 0, 1, "", "templeCode"},
+			{"PLAC", 0, 1, "", "placeName"},
+			{"STAT", 0, 1, "LDS_ORDINANCE.STAT", "ordinanceStatus"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+		"LDS_ORDINANCE.STAT": {
+			{"DATE", 1, 1, "", "changeDate"},
+		},
+		"LDS_CHILD_SEALING": {
+			include("LDS_ORDINANCE"),
+			{"FAMC", 1, 1, "", "pointer"},
+		},
+
+		"FAM": {
+			{"RESN", 0, 1, "", "restriction"},
+			{"ANUL", 0, many, "FAMILY_EVENT", ""},
+			{"CENS", 0, many, "FAMILY_EVENT", ""},
+			{"DIV", 0, many, "FAMILY_EVENT", ""},
+			{"DIVF", 0, many, "FAMILY_EVENT", ""},
+			{"ENGA", 0, many, "FAMILY_EVENT", ""},
+			{"MARB", 0, many, "FAMILY_EVENT", ""},
+			{"MARC", 0, many, "FAMILY_EVENT", ""},
+			{"MARR", 0, many, "FAMILY_EVENT", ""},
+			{"MARL", 0, many, "FAMILY_EVENT", ""},
+			{"MARS", 0, many, "FAMILY_EVENT", ""},
+			{"RESI", 0, many, "FAMILY_EVENT", ""},
+			{"EVEN", 0, many, "FAMILY_EVENT", "eventDescriptor"},
+			{"HUSB", 0, 1, "", "pointer"},
+			{"WIFE", 0, 1, "", "pointer"},
+			{"CHIL", 0, many, "", "pointer"},
+			{"NCHI", 0, 1, "", "count"},
+			{"SUBM", 0, many, "", "pointer"},
+			{"SLGS", 0, many, "LDS_ORDINANCE", ""},
+			include("RECORD"),
+			include("CITATIONS"),
+		},
+
+		"INDI": {
+			{"RESN", 0, 1, "", "restriction"},
+			{"NAME", 0, many, "PERSONAL_NAME", "personalName"},
+			{"SEX", 0, 1, "", "sex"},
+			{"BIRT", 0, many, "BIRTH_EVENT", ""},
+			{"CHR", 0, many, "BIRTH_EVENT", ""},
+			{"ADOP", 0, many, "ADOPTION_EVENT", ""},
+			{"DEAT", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"BURI", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"CREM", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"BAPM", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"BARM", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"BASM", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"BLES", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"CHRA", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"CONF", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"FCOM", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"ORDN", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"NATU", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"EMIG", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"IMMI", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"CENS", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"PROB", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"WILL", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"GRAD", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"RETI", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"EVEN", 0, many, "INDIVIDUAL_EVENT", "eventDescriptor"},
+			{"CAST", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"DSCR", 0, many, "INDIVIDUAL_EVENT", "description"},
+			{"EDUC", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"IDNO", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"NATI", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"NCHI", 0, many, "INDIVIDUAL_EVENT", "count"},
+			{"NMR", 0, many, "INDIVIDUAL_EVENT", "count"},
+			{"OCCU", 0, many, "INDIVIDUAL_EVENT", "occupation"},
+			{"PROP", 0, many, "INDIVIDUAL_EVENT", "description"},
+			{"RELI", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"RESI", 0, many, "INDIVIDUAL_EVENT", ""},
+			{"SSN", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"TITL", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"FACT", 0, many, "INDIVIDUAL_EVENT", "attribute"},
+			{"BAPL", 0, many, "LDS_ORDINANCE", ""},
+			{"CONL", 0, many, "LDS_ORDINANCE", ""},
+			{"ENDL", 0, many, "LDS_ORDINANCE", ""},
+			{"SLGC", 0, many, "LDS_CHILD_SEALING", ""},
+			{"FAMC", 0, many, "CHILD_TO_FAMILY", "pointer"},
+			{"FAMS", 0, many, "SPOUSE_TO_FAMILY", "pointer"},
+			{"SUBM", 0, many, "", "pointer"},
+			{"ASSO", 0, many, "ASSOCIATION", "pointer"},
+			{"ALIA", 0, many, "", "pointer"},
+			{"ANCI", 0, many, "", "pointer"},
+			{"DESI", 0, many, "", "pointer"},
+			{"RFN", 0, 1, "", "rfn"},
+			{"AFN", 0, 1, "", "afn"},
+			include("RECORD"),
+			include("CITATIONS"),
+		},
+		"PERSONAL_NAME": {
+			include("NAME_PIECES"),
+			{"TYPE", 0, 1, "", "nameType"},
+			{"FONE", 0, many, "NAME_VARIANT", "personalName"},
+			{"ROMN", 0, many, "NAME_VARIANT", "personalName"},
+		},
+		"NAME_VARIANT": {
+			{"TYPE", 1, 1, "", "type"},
+			include("NAME_PIECES"),
+		},
+		"NAME_PIECES": {
+			{"NPFX", 0, 1, "", "namePrefix"},
+			{"GIVN", 0, 1, "", "nameGiven"},
+			{"NICK", 0, 1, "", "nameNickname"},
+			{"SPFX", 0, 1, "", "namePrefix"},
+			{"SURN", 0, 1, "", "nameSurname"},
+			{"NSFX", 0, 1, "", "nameSuffix"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+		"CHILD_TO_FAMILY": {
+			{"PEDI", 0, 1, "", "pedigree"},
+			{"STAT", 0, 1, "", "childLinkageStatus"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"SPOUSE_TO_FAMILY": {
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"ASSOCIATION": {
+			{"RELA", 1, 1, "", "relation"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+
+		"OBJE": {
+			{"FILE", 1, many, "OBJE.FILE", "fileName"},
+			include("RECORD"),
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+		"OBJE.FILE": {
+			{"FORM", 1, 1, "OBJE.FORM", "mediaFormat"},
+			{"TITL", 0, 1, "", "descriptiveTitle"},
+		},
+		"OBJE.FORM": {
+			{"TYPE", 0, 1, "", "mediaType"},
+		},
+
+		"NOTE": {
+			include("TEXT"),
+			include("RECORD"),
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+
+		"REPO": {
+			{"NAME", 1, 1, "", "repositoryName"},
+			include("ADDRESS_STRUCTURE"),
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			include("RECORD"),
+		},
+
+		"SOUR": {
+			{"DATA", 0, 1, "SOUR.DATA", ""},
+			{"AUTH", 0, 1, "TEXT", "text"},
+			{"TITL", 0, 1, "TEXT", "text"},
+			{"ABBR", 0, 1, "", "sourceAbbreviation"},
+			{"PUBL", 0, 1, "TEXT", "text"},
+			{"TEXT", 0, 1, "TEXT", "text"},
+			{"REPO", 0, many, "REPOSITORY_CITATION", ""},
+			include("RECORD"),
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"OBJE", 0, many, "MULTIMEDIA_LINK", ""},
+		},
+		"SOUR.DATA": {
+			{"EVEN", 0, many, "SOUR.DATA.EVEN", "eventsRecorded"},
+			{"AGNC", 0, 1, "", "agency"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+		},
+		"SOUR.DATA.EVEN": {
+			{"DATE", 0, 1, "", "date"},
+			{"PLAC", 0, 1, "", "placeName"},
+		},
+		"REPOSITORY_CITATION": {
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"CALN", 0, many, "CALN", "callNumber"},
+		},
+		"CALN": {
+			{"MEDI", 0, 1, "", "sourceMediaType"},
+		},
+
+		"SUBM": {
+			{"NAME", 1, 1, "", "submitterName"},
+			include("ADDRESS_STRUCTURE"),
+			{"OBJE", 0, many, "MULTIMEDIA_LINK", ""},
+			{"LANG", 0, 3, "", "language"},
+			{"RFN", 0, 1, "", "rfn"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			include("RECORD"),
+		},
+
+		"SUBN": {
+			{"SUBM", 0, 1, "", "pointer"},
+			{"FAMF", 0, 1, "", "familyFile"},
+			{"TEMP", 0, 1, "", "templeCode"},
+			{"ANCE", 0, 1, "", "generations"},
+			{"DESC", 0, 1, "", "generations"},
+			{"ORDI", 0, 1, "", "yesNo"},
+			{"RIN", 0, 1, "", "rin"},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", "text"},
+			{"CHAN", 0, 1, "CHAN", ""},
+		},
+	},
+	Values: map[string]ValueType{
+		"pointer":            {Pointer: true},
+		"text":               {MaxLength: 248},
+		"systemID":           {MaxLength: 20},
+		"version":            {MaxLength: 15},
+		"productName":        {MaxLength: 90},
+		"name":               {MaxLength: 90},
+		"date":               {MaxLength: 35},
+		"changeDate":         {MaxLength: 11},
+		"time":               {MaxLength: 12},
+		"fileName":           {MaxLength: 259},
+		"copyright":          {MaxLength: 248},
+		"language":           {MaxLength: 15},
+		"placeForm":          {MaxLength: 120},
+		"placeName":          {MaxLength: 120},
+		"gedcomForm":         {Enum: []string{"LINEAGE-LINKED"}},
+		"charset":            {Enum: []string{"ANSEL", "UTF-8", "UNICODE", "ASCII"}},
+		"addressLine":        {MaxLength: 60},
+		"postalCode":         {MaxLength: 10},
+		"phone":              {MaxLength: 25},
+		"email":              {MaxLength: 120},
+		"url":                {MaxLength: 120},
+		"type":               {MaxLength: 90},
+		"refn":               {MaxLength: 20},
+		"rin":                {MaxLength: 12},
+		"rfn":                {MaxLength: 90},
+		"afn":                {MaxLength: 12},
+		"page":               {MaxLength: 248},
+		"eventType":          {MaxLength: 15},
+		"role":               {MaxLength: 15},
+		"quality":            {Enum: []string{"0", "1", "2", "3"}},
+		"descriptiveTitle":   {MaxLength: 248},
+		"mediaFormat":        {Enum: []string{"BMP", "GIF", "JPG", "OLE", "PCX", "TIF", "WAV"}},
+		"mediaType":          {Enum: []string{"AUDIO", "BOOK", "CARD", "ELECTRONIC", "FICHE", "FILM", "MAGAZINE", "MANUSCRIPT", "MAP", "NEWSPAPER", "PHOTO", "TOMBSTONE", "VIDEO"}},
+		"sourceMediaType":    {Enum: []string{"AUDIO", "BOOK", "CARD", "ELECTRONIC", "FICHE", "FILM", "MAGAZINE", "MANUSCRIPT", "MAP", "NEWSPAPER", "PHOTO", "TOMBSTONE", "VIDEO"}},
+		"agency":             {MaxLength: 120},
+		"religion":           {MaxLength: 90},
+		"cause":              {MaxLength: 90},
+		"restriction":        {Enum: []string{"CONFIDENTIAL", "LOCKED", "PRIVACY"}},
+		"latitude":           {MaxLength: 10},
+		"longitude":          {MaxLength: 11},
+		"age":                {MaxLength: 12},
+		"adoptedBy":          {Enum: []string{"HUSB", "WIFE", "BOTH"}},
+		"templeCode":         {MaxLength: 5},
+		"ordinanceStatus":    {MaxLength: 10},
+		"eventDescriptor":    {MaxLength: 90},
+		"count":              {MaxLength: 3},
+		"personalName":       {MaxLength: 120},
+		"sex":                {Enum: []string{"M", "F", "U"}},
+		"attribute":          {MaxLength: 120},
+		"occupation":         {MaxLength: 90},
+		"description":        {MaxLength: 248},
+		"nameType":           {Enum: []string{"AKA", "BIRTH", "IMMIGRANT", "MAIDEN", "MARRIED"}},
+		"namePrefix":         {MaxLength: 30},
+		"nameGiven":          {MaxLength: 120},
+		"nameNickname":       {MaxLength: 30},
+		"nameSurname":        {MaxLength: 120},
+		"nameSuffix":         {MaxLength: 30},
+		"pedigree":           {Enum: []string{"ADOPTED", "BIRTH", "FOSTER", "SEALING"}},
+		"childLinkageStatus": {Enum: []string{"CHALLENGED", "DISPROVEN", "PROVEN"}},
+		"relation":           {MaxLength: 25},
+		"repositoryName":     {MaxLength: 90},
+		"sourceAbbreviation": {MaxLength: 60},
+		"eventsRecorded":     {MaxLength: 90},
+		"callNumber":         {MaxLength: 120},
+		"submitterName":      {MaxLength: 60},
+		"familyFile":         {MaxLength: 120},
+		"generations":        {MaxLength: 4},
+		"yesNo":              {Enum: []string{"YES", "NO"}},
+	},
+}
+
+// Grammar70 is the grammar of GEDCOM 7.0, which drops CONC, CHAR and
+// submission records, lifts the length limits of 5.5.1 and adds shared
+// notes, identifiers and enumerations of its own.
+var Grammar70 = deriveGrammar(Grammar551, "7.0", grammar70Changes, map[string]ValueType{
+	"pointer":         {Pointer: true},
+	"sex":             {Enum: []string{"M", "F", "X", "U"}},
+	"quality":         {Enum: []string{"0", "1", "2", "3"}},
+	"restriction":     {Enum: []string{"CONFIDENTIAL", "LOCKED", "PRIVACY"}, List: true},
+	"pedigree":        {Enum: []string{"ADOPTED", "BIRTH", "FOSTER", "SEALING", "OTHER"}},
+	"adoptedBy":       {Enum: []string{"HUSB", "WIFE", "BOTH"}},
+	"nameType":        {Enum: []string{"AKA", "BIRTH", "IMMIGRANT", "MAIDEN", "MARRIED", "PROFESSIONAL", "OTHER"}},
+	"sourceMediaType": {Enum: []string{"AUDIO", "BOOK", "CARD", "ELECTRONIC", "FICHE", "FILM", "MAGAZINE", "MANUSCRIPT", "MAP", "NEWSPAPER", "PHOTO", "TOMBSTONE", "VIDEO", "OTHER"}},
+	"role":            {Enum: []string{"CHIL", "CLERGY", "FATH", "FRIEND", "GODP", "HUSB", "MOTH", "MULTIPLE", "NGHBR", "OFFICIATOR", "PARENT", "SPOU", "WIFE", "WITN", "OTHER"}},
+	"ordinanceStatus": {Enum: []string{"BIC", "CANCELED", "CHILD", "COMPLETED", "EXCLUDED", "DNS", "DNS_CAN", "INFANT", "PRE_1970", "STILLBORN", "SUBMITTED", "UNCLEARED"}},
+	"medium":          {Enum: []string{"AUDIO", "BOOK", "CARD", "ELECTRONIC", "FICHE", "FILM", "MAGAZINE", "MANUSCRIPT", "MAP", "NEWSPAPER", "PHOTO", "TOMBSTONE", "VIDEO", "OTHER"}},
+})
+
+// grammar70Changes lists, for each structure that differs in 7.0, the tags
+// removed and the rules added or replaced.
+var grammar70Changes = map[string]struct {
+	remove []string
+	rules  []Rule
+}{
+	"GEDCOM": {
+		remove: []string{"SUBN"},
+		rules:  []Rule{{"SNOTE", 0, many, "SNOTE", ""}},
+	},
+	"HEAD": {
+		remove: []string{"CHAR", "FILE"},
+		rules: []Rule{
+			{"SUBM", 0, 1, "", "pointer"},
+			{"SCHMA", 0, 1, "HEAD.SCHMA", ""},
+			{"NOTE", 0, 1, "NOTE_STRUCTURE", ""},
+			{"SNOTE", 0, 1, "", "pointer"},
+		},
+	},
+	"HEAD.SCHMA": {
+		rules: []Rule{{"TAG", 0, many, "", ""}},
+	},
+	"HEAD.GEDC": {
+		remove: []string{"FORM"},
+	},
+	"TEXT": {
+		remove: []string{"CONC"},
+	},
+	"ADDRESS_STRUCTURE": {
+		rules: []Rule{
+			{"PHON", 0, many, "", ""},
+			{"EMAIL", 0, many, "", ""},
+			{"FAX", 0, many, "", ""},
+			{"WWW", 0, many, "", ""},
+		},
+	},
+	"ADDR": {
+		rules: []Rule{{"CONT", 0, many, "", ""}},
+	},
+	"RECORD": {
+		remove: []string{"RIN"},
+		rules: []Rule{
+			{"UID", 0, many, "", ""},
+			{"EXID", 0, many, "EXID", ""},
+			{"CREA", 0, 1, "CREA", ""},
+		},
+	},
+	"EXID": {
+		rules: []Rule{{"TYPE", 0, 1, "", ""}},
+	},
+	"CREA": {
+		rules: []Rule{{"DATE", 1, 1, "CHAN.DATE", ""}},
+	},
+	"NOTE_STRUCTURE": {
+		rules: []Rule{
+			{"MIME", 0, 1, "", ""},
+			{"LANG", 0, 1, "", ""},
+			{"TRAN", 0, many, "NOTE_TRANSLATION", ""},
+		},
+	},
+	"NOTE_TRANSLATION": {
+		rules: []Rule{
+			include("TEXT"),
+			{"MIME", 0, 1, "", ""},
+			{"LANG", 0, 1, "", ""},
+		},
+	},
+	"CITATIONS": {
+		rules: []Rule{{"SNOTE", 0, many, "", "pointer"}},
+	},
+	"SOURCE_CITATION": {
+		remove: []string{"TEXT", "CONC"},
+	},
+	"SOURCE_CITATION.EVEN": {
+		rules: []Rule{{"PHRASE", 0, 1, "", ""}, {"ROLE", 0, 1, "PHRASE", "role"}},
+	},
+	"SOURCE_CITATION.DATA": {
+		rules: []Rule{{"TEXT", 0, many, "NOTE_TRANSLATION", ""}},
+	},
+	"PHRASE": {
+		rules: []Rule{{"PHRASE", 0, 1, "", ""}},
+	},
+	"DATE": {
+		rules: []Rule{{"TIME", 0, 1, "", ""}, {"PHRASE", 0, 1, "", ""}},
+	},
+	"MULTIMEDIA_LINK": {
+		remove: []string{"FILE"},
+		rules:  []Rule{{"CROP", 0, 1, "CROP", ""}},
+	},
+	"CROP": {
+		rules: []Rule{
+			{"TOP", 0, 1, "", ""},
+			{"LEFT", 0, 1, "", ""},
+			{"HEIGHT", 0, 1, "", ""},
+			{"WIDTH", 0, 1, "", ""},
+		},
+	},
+	"EVENT_DETAIL": {
+		rules: []Rule{
+			{"DATE", 0, 1, "DATE", ""},
+			{"PHRASE", 0, 1, "", ""},
+			{"SDATE", 0, 1, "DATE", ""},
+			{"ASSO", 0, many, "ASSOCIATION", "pointer"},
+			{"UID", 0, many, "", ""},
+		},
+	},
+	"PLACE_STRUCTURE": {
+		remove: []string{"FONE", "ROMN"},
+		rules: []Rule{
+			{"LANG", 0, 1, "", ""},
+			{"TRAN", 0, many, "PLACE_TRANSLATION", ""},
+			{"EXID", 0, many, "EXID", ""},
+			{"SNOTE", 0, many, "", "pointer"},
+		},
+	},
+	"PLACE_TRANSLATION": {
+		rules: []Rule{{"LANG", 1, 1, "", ""}},
+	},
+	"INDIVIDUAL_EVENT": {
+		rules: []Rule{{"AGE", 0, 1, "PHRASE", "age"}},
+	},
+	"SPOUSE_AGE": {
+		rules: []Rule{{"AGE", 1, 1, "PHRASE", "age"}},
+	},
+	"ADOPTION_EVENT.FAMC": {
+		rules: []Rule{{"ADOP", 0, 1, "PHRASE", "adoptedBy"}},
+	},
+	"LDS_ORDINANCE": {
+		rules: []Rule{{"DATE", 0, 1, "DATE", ""}},
+	},
+	"FAM": {
+		rules: []Rule{
+			{"FACT", 0, many, "FAMILY_EVENT", ""},
+			{"NCHI", 0, many, "FAMILY_EVENT", ""},
+			{"HUSB", 0, 1, "PHRASE", "pointer"},
+			{"WIFE", 0, 1, "PHRASE", "pointer"},
+			{"CHIL", 0, many, "PHRASE", "pointer"},
+			{"ASSO", 0, many, "ASSOCIATION", "pointer"},
+			{"NO", 0, many, "NON_EVENT", ""},
+		},
+	},
+	"INDI": {
+		remove: []string{"RFN", "AFN"},
+		rules: []Rule{
+			{"ALIA", 0, many, "PHRASE", "pointer"},
+			{"NO", 0, many, "NON_EVENT", ""},
+			{"INIL", 0, many, "LDS_ORDINANCE", ""},
+		},
+	},
+	"NON_EVENT": {
+		rules: []Rule{
+			{"DATE", 0, 1, "PHRASE", ""},
+			{"NOTE", 0, many, "NOTE_STRUCTURE", ""},
+			{"SNOTE", 0, many, "", "pointer"},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+		},
+	},
+	"PERSONAL_NAME": {
+		remove: []string{"FONE", "ROMN"},
+		rules: []Rule{
+			{"TYPE", 0, 1, "PHRASE", "nameType"},
+			{"TRAN", 0, many, "NAME_TRANSLATION", ""},
+		},
+	},
+	"NAME_TRANSLATION": {
+		rules: []Rule{
+			{"LANG", 1, 1, "", ""},
+			include("NAME_PIECES"),
+		},
+	},
+	"CHILD_TO_FAMILY": {
+		remove: []string{"STAT"},
+		rules:  []Rule{{"PEDI", 0, 1, "PHRASE", "pedigree"}},
+	},
+	"ASSOCIATION": {
+		remove: []string{"RELA"},
+		rules: []Rule{
+			{"PHRASE", 0, 1, "", ""},
+			{"ROLE", 1, 1, "PHRASE", "role"},
+		},
+	},
+	"OBJE": {
+		rules: []Rule{{"RESN", 0, 1, "", "restriction"}},
+	},
+	"OBJE.FILE": {
+		rules: []Rule{{"TRAN", 0, many, "OBJE.FILE.TRAN", ""}},
+	},
+	"OBJE.FORM": {
+		remove: []string{"TYPE"},
+		rules:  []Rule{{"MEDI", 0, 1, "PHRASE", "medium"}},
+	},
+	"OBJE.FILE.TRAN": {
+		rules: []Rule{{"FORM", 1, 1, "", ""}},
+	},
+	"SNOTE": {
+		rules: []Rule{
+			{"CONT", 0, many, "", ""},
+			{"MIME", 0, 1, "", ""},
+			{"LANG", 0, 1, "", ""},
+			{"TRAN", 0, many, "NOTE_TRANSLATION", ""},
+			{"SOUR", 0, many, "SOURCE_CITATION", ""},
+			include("RECORD"),
+		},
+	},
+	"SOUR": {
+		rules: []Rule{{"TEXT", 0, 1, "NOTE_TRANSLATION", ""}},
+	},
+	"CALN": {
+		rules: []Rule{{"MEDI", 0, 1, "PHRASE", "sourceMediaType"}},
+	},
+	"SUBM": {
+		rules: []Rule{{"LANG", 0, many, "", ""}},
+	},
+}
+
+// deriveGrammar returns a copy of base with the changes applied and values
+// as its value types.
+func deriveGrammar(base *Grammar, version string, changes map[string]struct {
+	remove []string
+	rules  []Rule
+}, values map[string]ValueType) *Grammar {
+	gr := &Grammar{
+		Version:       version,
+		Structures:    make(map[string][]Rule),
+		Values:        values,
+		CaseSensitive: true,
+	}
+
+	for name, rules := range base.Structures {
+		gr.Structures[name] = append([]Rule(nil), rules...)
+	}
+
+	for name, c := range changes {
+		var rules []Rule
+		for _, r := range gr.Structures[name] {
+			if r.Tag == "" || !containsTag(c.remove, r.Tag) {
+				rules = append(rules, r)
+			}
+		}
+		for _, add := range c.rules {
+			replaced := false
+			for i, r := range rules {
+				if add.Tag != "" && r.Tag == add.Tag {
+					rules[i] = add
+					replaced = true
+				}
+			}
+			if !replaced {
+				rules = append(rules, add)
+			}
+		}
+		gr.Structures[name] = rules
+	}
+
+	return gr
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// rules returns the rules of a structure by tag, with included structures
+// spliced in.
+func (gr *Grammar) rules(structure string) map[string]Rule {
+	rules := make(map[string]Rule)
+	var add func(string, int)
+	add = func(name string, depth int) {
+		if depth > 10 {
+			return
+		}
+		for _, r := range gr.Structures[name] {
+			if r.Tag == "" {
+				add(r.Structure, depth+1)
+				continue
+			}
+			rules[r.Tag] = r
+		}
+	}
+	add(structure, 0)
+	return rules
+}
+
+// checkValue returns a description of what is wrong with a value, or an empty
+// string if it is acceptable.
+func (gr *Grammar) checkValue(r Rule, value string) string {
+	vt, ok := gr.Values[r.Value]
+	if !ok {
+		return ""
+	}
+
+	if vt.Pointer {
+		if len(value) < 3 || !strings.HasPrefix(value, "@") || !strings.HasSuffix(value, "@") {
+			return "is not a pointer"
+		}
+		return ""
+	}
+
+	if vt.MaxLength > 0 && len([]rune(value)) > vt.MaxLength {
+		return "is longer than " + strconv.Itoa(vt.MaxLength) + " characters"
+	}
+
+	if len(vt.Enum) > 0 {
+		values := []string{value}
+		if vt.List {
+			values = strings.Split(value, ",")
+		}
+		for _, v := range values {
+			if !gr.enumerated(vt.Enum, strings.TrimSpace(v)) {
+				return "is not one of " + strings.Join(vt.Enum, ", ")
+			}
+		}
+	}
+
+	return ""
+}
+
+func (gr *Grammar) enumerated(enum []string, value string) bool {
+	for _, e := range enum {
+		if e == value || (!gr.CaseSensitive && strings.EqualFold(e, value)) {
+			return true
+		}
+	}
+	return false
+}
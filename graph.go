@@ -0,0 +1,216 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+)
+
+// Components partitions the individuals into separate family trees, where
+// each tree holds everyone connected by parent, child or spouse links. Trees
+// are ordered largest first and people within a tree keep their file order.
+func (g *Gedcom) Components() [][]*IndividualRecord {
+	parent := make(map[*IndividualRecord]*IndividualRecord)
+	var find func(i *IndividualRecord) *IndividualRecord
+	find = func(i *IndividualRecord) *IndividualRecord {
+		p, found := parent[i]
+		if !found || p == i {
+			parent[i] = i
+			return i
+		}
+		root := find(p)
+		parent[i] = root
+		return root
+	}
+	union := func(a, b *IndividualRecord) {
+		if a != nil && b != nil {
+			parent[find(a)] = find(b)
+		}
+	}
+
+	for _, l := range g.familyLinks() {
+		members := append(append([]*IndividualRecord(nil), l.spouses...), l.children...)
+		for _, m := range members {
+			union(members[0], m)
+		}
+	}
+
+	order := make(map[*IndividualRecord]int)
+	var trees [][]*IndividualRecord
+	for _, i := range g.Individual {
+		root := find(i)
+		n, found := order[root]
+		if !found {
+			n = len(trees)
+			order[root] = n
+			trees = append(trees, nil)
+		}
+		trees[n] = append(trees[n], i)
+	}
+
+	sort.SliceStable(trees, func(j, k int) bool {
+		return len(trees[j]) > len(trees[k])
+	})
+	return trees
+}
+
+// Isolated returns the individuals who belong to no family, either as a
+// spouse or as a child.
+func (g *Gedcom) Isolated() []*IndividualRecord {
+	linked := make(map[*IndividualRecord]bool)
+	for _, l := range g.familyLinks() {
+		for _, m := range l.spouses {
+			linked[m] = true
+		}
+		for _, m := range l.children {
+			linked[m] = true
+		}
+	}
+
+	var people []*IndividualRecord
+	for _, i := range g.Individual {
+		if !linked[i] {
+			people = append(people, i)
+		}
+	}
+	return people
+}
+
+// AncestorCycles returns each group of individuals who are, through their
+// parent links, their own ancestors. Such cycles are always data errors,
+// usually caused by a wrong FAMC or FAMS link.
+func (g *Gedcom) AncestorCycles() [][]*IndividualRecord {
+	parents := make(map[*IndividualRecord][]*IndividualRecord)
+	for _, l := range g.familyLinks() {
+		for _, c := range l.children {
+			for _, p := range l.spouses {
+				parents[c] = appendPerson(parents[c], p)
+			}
+		}
+	}
+
+	// Tarjan's strongly connected components algorithm
+	var (
+		cycles  [][]*IndividualRecord
+		stack   []*IndividualRecord
+		index   = make(map[*IndividualRecord]int)
+		low     = make(map[*IndividualRecord]int)
+		onStack = make(map[*IndividualRecord]bool)
+		next    = 1
+	)
+
+	var connect func(i *IndividualRecord)
+	connect = func(i *IndividualRecord) {
+		index[i], low[i] = next, next
+		next++
+		stack = append(stack, i)
+		onStack[i] = true
+
+		selfParent := false
+		for _, p := range parents[i] {
+			if p == i {
+				selfParent = true
+			}
+			if index[p] == 0 {
+				connect(p)
+				if low[p] < low[i] {
+					low[i] = low[p]
+				}
+			} else if onStack[p] && index[p] < low[i] {
+				low[i] = index[p]
+			}
+		}
+
+		if low[i] != index[i] {
+			return
+		}
+		var group []*IndividualRecord
+		for {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[p] = false
+			group = append(group, p)
+			if p == i {
+				break
+			}
+		}
+		if len(group) > 1 || selfParent {
+			cycles = append(cycles, group)
+		}
+	}
+
+	for _, i := range g.Individual {
+		if index[i] == 0 {
+			connect(i)
+		}
+	}
+	return cycles
+}
+
+// families returns every family in g, including those only reached through
+// an individual's FAMC or FAMS links.
+func (g *Gedcom) families() []*FamilyRecord {
+	seen := make(map[*FamilyRecord]bool)
+	var fams []*FamilyRecord
+	add := func(f *FamilyRecord) {
+		if f != nil && !seen[f] {
+			seen[f] = true
+			fams = append(fams, f)
+		}
+	}
+
+	for _, f := range g.Family {
+		add(f)
+	}
+	for _, i := range g.Individual {
+		for _, l := range i.Parents {
+			add(l.Family)
+		}
+		for _, l := range i.Family {
+			add(l.Family)
+		}
+	}
+	return fams
+}
+
+// familyLink holds the spouses and children of a family.
+type familyLink struct {
+	spouses  []*IndividualRecord
+	children []*IndividualRecord
+}
+
+// familyLinks returns the spouses and children of every family in g, in the
+// order of families. Both sides of each link count, the HUSB, WIFE and CHIL
+// of the family and the FAMS and FAMC of the individual, so that a link
+// recorded on one side only is still followed.
+func (g *Gedcom) familyLinks() []*familyLink {
+	fams := g.families()
+	links := make(map[*FamilyRecord]*familyLink)
+	for _, f := range fams {
+		l := &familyLink{}
+		l.spouses = appendPerson(appendPerson(nil, f.Husband), f.Wife)
+		l.children = f.Children()
+		links[f] = l
+	}
+	for _, i := range g.Individual {
+		for _, fl := range i.Family {
+			if l := links[fl.Family]; l != nil {
+				l.spouses = appendPerson(l.spouses, i)
+			}
+		}
+		for _, fl := range i.Parents {
+			if l := links[fl.Family]; l != nil {
+				l.children = appendPerson(l.children, i)
+			}
+		}
+	}
+
+	var result []*familyLink
+	for _, f := range fams {
+		result = append(result, links[f])
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	ag := decodeFile(t, "testdata/allged.ged")
+	trees := pg.Components()
+
+	intTestCases{
+		{"Tree count was [%d]", 3, len(trees)},
+		{"Largest tree size was [%d]", 12, len(trees[0])},
+		{"Second tree size was [%d]", 2, len(trees[1])},
+		{"allged.ged tree count was [%d]", 1, len(ag.Components())},
+	}.run(t)
+
+	isolated := pg.Isolated()
+
+	intTestCases{
+		{"Isolated count was [%d]", 1, len(isolated)},
+		{"allged.ged isolated count was [%d]", 0, len(ag.Isolated())},
+	}.run(t)
+
+	stringTestCases{
+		{"Largest tree first person", "I1", trees[0][0].Xref},
+		{"Isolated person", "I11", isolated[0].Xref},
+		{"Smallest tree person", "I11", trees[2][0].Xref},
+	}.run(t)
+}
+
+func TestAncestorCycles(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	ag := decodeFile(t, "testdata/allged.ged")
+	cycles := pg.AncestorCycles()
+
+	intTestCases{
+		{"Cycle count was [%d]", 1, len(cycles)},
+		{"Cycle size was [%d]", 2, len(cycles[0])},
+		{"allged.ged cycle count was [%d]", 0, len(ag.AncestorCycles())},
+	}.run(t)
+
+	self := &IndividualRecord{Xref: "SELF"}
+	f := &FamilyRecord{Husband: self, Child: []*ChildRecord{{Person: self}}}
+	sg := &Gedcom{Individual: []*IndividualRecord{self}, Family: []*FamilyRecord{f}}
+
+	intTestCases{
+		{"Self parent cycle count was [%d]", 1, len(sg.AncestorCycles())},
+	}.run(t)
+}
+
+func TestOneSidedLinks(t *testing.T) {
+	og := decodeFile(t, "testdata/onesided.ged")
+
+	cycles := og.AncestorCycles()
+	if len(cycles) != 1 {
+		t.Fatalf("Cycle count was [%d], expected [1]", len(cycles))
+	}
+
+	stringTestCases{
+		{"Cycle through a FAMC without CHIL", "I2 I1", xrefs(cycles[0])},
+		{"Isolated", "I5", xrefs(og.Isolated())},
+		{"FAMC without CHIL joins the tree", "I3 I4", xrefs(og.Components()[1])},
+	}.run(t)
+}
@@ -0,0 +1,232 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+)
+
+// ImplexGeneration summarises pedigree collapse in one generation of ancestors.
+type ImplexGeneration struct {
+	Generation int
+	Possible   uint64  // 2^Generation ancestor slots
+	Recorded   int     // slots filled by a known ancestor
+	Distinct   int     // different people filling those slots
+	Implex     float64 // percentage of recorded slots filled by someone already counted
+}
+
+// DuplicateAncestor is an ancestor who appears on more than one line.
+type DuplicateAncestor struct {
+	Person      *IndividualRecord
+	Generations []int    // the generations in which they appear, nearest first
+	Lines       []uint64 // the number of lines on which they appear in each of those generations
+	Ahnentafel  []uint64 // their lowest Ahnentafel number in each of those generations
+}
+
+// InbreedingCoefficient returns Wright's coefficient of inbreeding for ind,
+// the sum over every path linking the birth father and mother through a
+// common ancestor A of (1/2)^(n+1) * (1 + F(A)), where n is the number of
+// links in the path. It is worked out as the coefficient of kinship of the
+// parents, in time that grows with the square of the number of ancestors
+// rather than with the number of paths. It is zero if either parent is
+// unknown.
+func InbreedingCoefficient(ind *IndividualRecord) float64 {
+	p := newBirthPedigree(ind)
+	k := &kinship{pedigree: p, memo: make(map[[2]*IndividualRecord]float64)}
+	parents := p.parents[ind]
+	return k.coefficient(parents[0], parents[1])
+}
+
+// kinship works out coefficients of kinship within a birth pedigree.
+type kinship struct {
+	pedigree *birthPedigree
+	memo     map[[2]*IndividualRecord]float64
+	outbred  bool // whether to ignore the inbreeding of common ancestors
+}
+
+// coefficient returns the coefficient of kinship of a and b, the chance that
+// genes drawn at random from each are identical by descent. The one of a and
+// b later in the pedigree, who cannot be an ancestor of the other, is
+// replaced by their parents.
+func (k *kinship) coefficient(a *IndividualRecord, b *IndividualRecord) float64 {
+	if a == nil || b == nil {
+		return 0
+	}
+	if k.pedigree.order[a] < k.pedigree.order[b] {
+		a, b = b, a
+	}
+	key := [2]*IndividualRecord{a, b}
+	if f, found := k.memo[key]; found {
+		return f
+	}
+
+	var f float64
+	parents := k.pedigree.parents[a]
+	switch {
+	case a == b && k.outbred:
+		f = 0.5
+	case a == b:
+		f = (1 + k.coefficient(parents[0], parents[1])) / 2
+	default:
+		f = (k.coefficient(parents[0], b) + k.coefficient(parents[1], b)) / 2
+	}
+	k.memo[key] = f
+	return f
+}
+
+// birthPedigree holds the birth father and mother of some people and of each
+// of their ancestors. A parent who is also a descendant, as happens only in an
+// ancestor cycle, is left out so that no one is their own ancestor.
+type birthPedigree struct {
+	parents map[*IndividualRecord][2]*IndividualRecord
+	order   map[*IndividualRecord]int // parents before their children
+}
+
+func newBirthPedigree(people ...*IndividualRecord) *birthPedigree {
+	p := &birthPedigree{
+		parents: make(map[*IndividualRecord][2]*IndividualRecord),
+		order:   make(map[*IndividualRecord]int),
+	}
+	onPath := make(map[*IndividualRecord]bool)
+
+	var visit func(*IndividualRecord)
+	visit = func(child *IndividualRecord) {
+		onPath[child] = true
+		var parents [2]*IndividualRecord
+		for n, parent := range []*IndividualRecord{child.Father(), child.Mother()} {
+			if parent == nil || onPath[parent] {
+				continue
+			}
+			parents[n] = parent
+			if _, done := p.parents[parent]; !done {
+				visit(parent)
+			}
+		}
+		onPath[child] = false
+		p.parents[child] = parents
+		p.order[child] = len(p.order)
+	}
+	for _, ind := range people {
+		if _, done := p.parents[ind]; !done {
+			visit(ind)
+		}
+	}
+	return p
+}
+
+// pedigreeSlots are the places in a generation of a pedigree filled by one
+// ancestor.
+type pedigreeSlots struct {
+	lines      uint64 // the number of lines of descent reaching the ancestor
+	ahnentafel uint64 // the lowest Ahnentafel number; zero beyond 63 generations
+}
+
+// generations returns the ancestors in each generation of the pedigree, up to
+// maxGen generations back, and the slots each fills. Lines are counted
+// generation by generation rather than followed one by one, as there may be
+// very many of them when a pedigree collapses. A maxGen of zero or less means
+// no limit.
+func (p *birthPedigree) generations(ind *IndividualRecord, maxGen int) []map[*IndividualRecord]*pedigreeSlots {
+	gens := []map[*IndividualRecord]*pedigreeSlots{{ind: {lines: 1, ahnentafel: 1}}}
+	for maxGen <= 0 || len(gens) <= maxGen {
+		next := make(map[*IndividualRecord]*pedigreeSlots)
+		for child, slots := range gens[len(gens)-1] {
+			for n, parent := range p.parents[child] {
+				if parent == nil {
+					continue
+				}
+				var number uint64
+				if slots.ahnentafel != 0 && slots.ahnentafel < 1<<62 {
+					number = slots.ahnentafel*2 + uint64(n)
+				}
+				s, ok := next[parent]
+				if !ok {
+					s = &pedigreeSlots{ahnentafel: number}
+					next[parent] = s
+				} else if number != 0 && (s.ahnentafel == 0 || number < s.ahnentafel) {
+					s.ahnentafel = number
+				}
+				s.lines += slots.lines
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		gens = append(gens, next)
+	}
+	return gens
+}
+
+// Implex returns the pedigree collapse of ind's ancestry for each generation
+// up to maxGen, following birth links only. A slot is counted as filled by
+// someone already counted unless it is the first of the nearest generation
+// in which that ancestor appears. A maxGen of zero or less means no limit.
+func Implex(ind *IndividualRecord, maxGen int) []*ImplexGeneration {
+	var gens []*ImplexGeneration
+	seen := make(map[*IndividualRecord]bool)
+
+	for n, people := range newBirthPedigree(ind).generations(ind, maxGen) {
+		gen := &ImplexGeneration{Generation: n, Distinct: len(people)}
+		if n < 64 {
+			gen.Possible = 1 << uint(n)
+		}
+		repeats := 0
+		for person, slots := range people {
+			gen.Recorded += int(slots.lines)
+			repeats += int(slots.lines)
+			if !seen[person] {
+				seen[person] = true
+				repeats--
+			}
+		}
+		gen.Implex = 100 * float64(repeats) / float64(gen.Recorded)
+		gens = append(gens, gen)
+	}
+	return gens
+}
+
+// DuplicateAncestors returns the birth ancestors of ind who appear on more
+// than one line within maxGen generations, with the number of lines and the
+// lowest Ahnentafel number in each generation in which they appear. They are
+// in order of first appearance, by generation and then Ahnentafel number.
+func DuplicateAncestors(ind *IndividualRecord, maxGen int) []*DuplicateAncestor {
+	found := make(map[*IndividualRecord]*DuplicateAncestor)
+	var order []*DuplicateAncestor
+	for n, people := range newBirthPedigree(ind).generations(ind, maxGen) {
+		var first []*DuplicateAncestor
+		for person, slots := range people {
+			d, ok := found[person]
+			if !ok {
+				d = &DuplicateAncestor{Person: person}
+				found[person] = d
+				first = append(first, d)
+			}
+			d.Generations = append(d.Generations, n)
+			d.Lines = append(d.Lines, slots.lines)
+			d.Ahnentafel = append(d.Ahnentafel, slots.ahnentafel)
+		}
+		sort.Slice(first, func(i, j int) bool {
+			a, b := first[i].Ahnentafel[0], first[j].Ahnentafel[0]
+			if a != b {
+				return a < b
+			}
+			return first[i].Person.Xref < first[j].Person.Xref
+		})
+		order = append(order, first...)
+	}
+
+	var dups []*DuplicateAncestor
+	for _, d := range order {
+		lines := uint64(0)
+		for _, l := range d.Lines {
+			lines += l
+		}
+		if lines > 1 {
+			dups = append(dups, d)
+		}
+	}
+	return dups
+}
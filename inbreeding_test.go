@@ -0,0 +1,115 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestInbreedingCoefficient(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+
+	var inbreedingTests = []struct {
+		xref    string
+		correct float64
+	}{
+		{"I9", 0.0625}, // child of first cousins
+		{"I7", 0},
+		{"I1", 0},
+		{"I12", 0}, // ancestor cycle, one parent only
+	}
+
+	for _, tt := range inbreedingTests {
+		if f := InbreedingCoefficient(person(t, pg, tt.xref)); f != tt.correct {
+			t.Errorf("Inbreeding coefficient of %s was [%v], expected [%v]", tt.xref, f, tt.correct)
+		}
+	}
+}
+
+func TestImplex(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	i9 := person(t, pg, "I9")
+
+	gens := Implex(i9, 0)
+
+	intTestCases{
+		{"Implex generation count was [%d]", 4, len(gens)},
+		{"Generation 3 recorded was [%d]", 4, gens[3].Recorded},
+		{"Generation 3 distinct was [%d]", 2, gens[3].Distinct},
+		{"Generation 3 possible was [%d]", 8, int(gens[3].Possible)},
+	}.run(t)
+
+	stringTestCases{
+		{"Generation 2 implex", "0.0", fmt.Sprintf("%.1f", gens[2].Implex)},
+		{"Generation 3 implex", "50.0", fmt.Sprintf("%.1f", gens[3].Implex)},
+	}.run(t)
+
+	dups := DuplicateAncestors(i9, 0)
+
+	intTestCases{
+		{"Duplicate ancestor count was [%d]", 2, len(dups)},
+	}.run(t)
+
+	stringTestCases{
+		{"Duplicate ancestor 0", "I1 [3] [2] [8]", fmt.Sprintf("%s %v %v %v", dups[0].Person.Xref, dups[0].Generations, dups[0].Lines, dups[0].Ahnentafel)},
+		{"Duplicate ancestor 1", "I2 [3] [2] [9]", fmt.Sprintf("%s %v %v %v", dups[1].Person.Xref, dups[1].Generations, dups[1].Lines, dups[1].Ahnentafel)},
+	}.run(t)
+}
+
+// brotherSisterMatings returns the family of a brother and sister, Hn and Wn,
+// after n generations of brother-sister matings from H0 and W0, with far too
+// many lines of descent to follow one by one.
+func brotherSisterMatings(n int) *FamilyRecord {
+	parents := &FamilyRecord{Husband: &IndividualRecord{Xref: "H0"}, Wife: &IndividualRecord{Xref: "W0"}}
+	for gen := 1; gen <= n; gen++ {
+		h := &IndividualRecord{Xref: fmt.Sprintf("H%d", gen), Sex: "M"}
+		w := &IndividualRecord{Xref: fmt.Sprintf("W%d", gen), Sex: "F"}
+		for _, i := range []*IndividualRecord{h, w} {
+			i.Parents = []*FamilyLinkRecord{{Family: parents}}
+			parents.Child = append(parents.Child, &ChildRecord{Person: i})
+		}
+		parents = &FamilyRecord{Husband: h, Wife: w}
+	}
+	return parents
+}
+
+func TestInbreedingCollapsed(t *testing.T) {
+
+	const generations = 40
+	child := brotherSisterMatings(generations + 1).Husband
+
+	// F(t) = (1 + 2F(t-1) + F(t-2)) / 4 for the child of t generations of
+	// brother-sister matings.
+	expected := []float64{0, 0}
+	for n := 1; n <= generations; n++ {
+		expected = append(expected, (1+2*expected[n]+expected[n-1])/4)
+	}
+	if f := InbreedingCoefficient(child); math.Abs(f-expected[generations+1]) > 1e-12 {
+		t.Errorf("Inbreeding coefficient was [%v], expected [%v]", f, expected[generations+1])
+	}
+
+	gens := Implex(child, 0)
+
+	intTestCases{
+		{"Implex generation count was [%d]", generations + 2, len(gens)},
+		{"Generation 30 distinct was [%d]", 2, gens[30].Distinct},
+		{"Generation 30 recorded was [%d]", 1 << 30, gens[30].Recorded},
+	}.run(t)
+
+	dups := DuplicateAncestors(child, 0)
+
+	intTestCases{
+		{"Duplicate ancestor count was [%d]", 2 * generations, len(dups)},
+	}.run(t)
+
+	stringTestCases{
+		{"Duplicate ancestor 0", "H39 [2] [2] [4]", fmt.Sprintf("%s %v %v %v", dups[0].Person.Xref, dups[0].Generations, dups[0].Lines, dups[0].Ahnentafel)},
+		{"Last duplicate ancestor", "W0 [41] [1099511627776] [2199023255553]", fmt.Sprintf("%s %v %v %v", dups[len(dups)-1].Person.Xref, dups[len(dups)-1].Generations, dups[len(dups)-1].Lines, dups[len(dups)-1].Ahnentafel)},
+	}.run(t)
+}
@@ -0,0 +1,133 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+)
+
+// referenceIndex records, for shared records, the records that refer to them.
+type referenceIndex struct {
+	citations map[*SourceRecord][]*CitationRecord
+	notes     map[*NoteRecord][]interface{}
+	objects   map[*ObjectRecord][]interface{}
+}
+
+// CitationsOf returns every citation of source s.
+func (g *Gedcom) CitationsOf(s *SourceRecord) []*CitationRecord {
+	return g.index().citations[s]
+}
+
+// ReferencesTo returns the records that refer to note n, such as
+// *IndividualRecord, *EventRecord or *CitationRecord.
+func (g *Gedcom) ReferencesTo(n *NoteRecord) []interface{} {
+	return g.index().notes[n]
+}
+
+// ReferencesToObject returns the records that refer to multimedia object o.
+func (g *Gedcom) ReferencesToObject(o *ObjectRecord) []interface{} {
+	return g.index().objects[o]
+}
+
+// Reindex discards the reverse link indexes so that they are rebuilt on next
+// use. It must be called after records have been modified directly.
+func (g *Gedcom) Reindex() {
+	g.refs = nil
+}
+
+func (g *Gedcom) index() *referenceIndex {
+	if g.refs != nil {
+		return g.refs
+	}
+
+	idx := &referenceIndex{
+		citations: make(map[*SourceRecord][]*CitationRecord),
+		notes:     make(map[*NoteRecord][]interface{}),
+		objects:   make(map[*ObjectRecord][]interface{}),
+	}
+
+	g.walk(func(rec interface{}, owner interface{}) {
+		if _, top := owner.(*Gedcom); top {
+			return
+		}
+		switch r := rec.(type) {
+		case *CitationRecord:
+			if r.Source != nil {
+				idx.citations[r.Source] = append(idx.citations[r.Source], r)
+			}
+		case *NoteRecord:
+			idx.notes[r] = append(idx.notes[r], owner)
+		case *ObjectRecord:
+			idx.objects[r] = append(idx.objects[r], owner)
+		}
+	})
+
+	g.refs = idx
+	return idx
+}
+
+var pkgPath = reflect.TypeOf(Gedcom{}).PkgPath()
+
+// walk calls fn for every reference from one record to another reachable from
+// g, passing the referenced record and the record holding the reference. The
+// contents of a record are only visited once however often it is referenced.
+func (g *Gedcom) walk(fn func(rec interface{}, owner interface{})) {
+	seen := make(map[interface{}]bool)
+
+	var visit func(v reflect.Value, owner interface{})
+	visit = func(v reflect.Value, owner interface{}) {
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type().PkgPath() != pkgPath {
+			return
+		}
+		rec := v.Interface()
+		if _, ok := rec.(*Place); ok {
+			// the place index is derived from events, not part of the record graph
+			return
+		}
+
+		fn(rec, owner)
+		if seen[rec] {
+			return
+		}
+		seen[rec] = true
+
+		s := v.Elem()
+		for i := 0; i < s.NumField(); i++ {
+			f := s.Field(i)
+			if !f.CanInterface() {
+				continue
+			}
+			switch f.Kind() {
+			case reflect.Ptr:
+				visit(f, rec)
+			case reflect.Struct:
+				visit(f.Addr(), rec)
+			case reflect.Slice:
+				for j := 0; j < f.Len(); j++ {
+					visit(f.Index(j), rec)
+				}
+			}
+		}
+	}
+
+	v := reflect.ValueOf(g)
+	seen[g] = true
+	s := v.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanInterface() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Ptr:
+			visit(f, g)
+		case reflect.Slice:
+			for j := 0; j < f.Len(); j++ {
+				visit(f.Index(j), g)
+			}
+		}
+	}
+}
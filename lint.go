@@ -0,0 +1,233 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// LintIssue is a departure from the GEDCOM grammar found by Lint.
+type LintIssue struct {
+	Line    int    // the line at fault, or zero for the file as a whole
+	Path    string // the tags leading to the line, such as INDI.BIRT.DATE
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// lintFrame is a structure open at some level while linting.
+type lintFrame struct {
+	level     int
+	line      int
+	path      string
+	structure string
+	checked   bool // whether the structure's substructures are known
+	counts    map[string]int
+}
+
+// Lint checks the lines read from r against gr, reporting tags that are not
+// allowed where they appear, substructures that occur too often or not at
+// all, enumerated values that are not allowed and values or lines that are
+// too long. User defined tags, which begin with an underscore, are allowed
+// anywhere and their substructures are not checked. If gr is nil the grammar
+// is chosen from the version given in the header.
+func Lint(r io.Reader, gr *Grammar) ([]LintIssue, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if gr == nil {
+		gr = Grammar551
+		if strings.HasPrefix(headerVersion(lines), "7") {
+			gr = Grammar70
+		}
+	}
+
+	var issues []LintIssue
+	report := func(line int, path string, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Line: line, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rules := make(map[string]map[string]Rule)
+	rulesOf := func(structure string) map[string]Rule {
+		if rs, ok := rules[structure]; ok {
+			return rs
+		}
+		rs := gr.rules(structure)
+		rules[structure] = rs
+		return rs
+	}
+
+	finish := func(f *lintFrame) {
+		if !f.checked {
+			return
+		}
+		where := f.path
+		if where == "" {
+			where = "the file"
+		}
+		for _, tag := range sortedTags(rulesOf(f.structure)) {
+			rule := rulesOf(f.structure)[tag]
+			n := f.counts[tag]
+			switch {
+			case n < rule.Min:
+				report(f.line, f.path, "%s is missing required %s", where, tag)
+			case rule.Max > 0 && n > rule.Max:
+				report(f.line, f.path, "%s occurs %d times in %s, at most %d allowed", tag, n, where, rule.Max)
+			}
+		}
+	}
+
+	stack := []*lintFrame{{level: -1, structure: "GEDCOM", checked: true, counts: make(map[string]int)}}
+
+	for n, raw := range lines {
+		lineNo := n + 1
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+
+		if gr.MaxLineLength > 0 && len([]rune(string(raw))) > gr.MaxLineLength {
+			report(lineNo, "", "line is longer than %d characters", gr.MaxLineLength)
+		}
+
+		s := &scanner{}
+		s.reset()
+		if _, err := s.nextTag(append(raw, '\n')); err != nil {
+			report(lineNo, "", "line is malformed: %v", err)
+			continue
+		}
+		level, tag, value := s.level, string(s.tag), string(s.value)
+
+		top := stack[len(stack)-1]
+		if level > top.level+1 {
+			report(lineNo, "", "level %d follows level %d", level, top.level)
+		}
+		for len(stack) > 1 && stack[len(stack)-1].level >= level {
+			finish(stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+		}
+
+		parent := stack[len(stack)-1]
+		path := tag
+		if parent.path != "" {
+			path = parent.path + "." + tag
+		}
+		frame := &lintFrame{level: level, line: lineNo, path: path, counts: make(map[string]int)}
+		stack = append(stack, frame)
+
+		if !parent.checked || strings.HasPrefix(tag, "_") {
+			continue
+		}
+		parent.counts[tag]++
+
+		rule, ok := rulesOf(parent.structure)[tag]
+		if !ok {
+			if parent.path == "" {
+				report(lineNo, path, "%s is not a record allowed in GEDCOM %s", tag, gr.Version)
+			} else {
+				report(lineNo, path, "%s is not allowed in %s", tag, parent.path)
+			}
+			continue
+		}
+
+		if problem := gr.checkValue(rule, value); problem != "" {
+			report(lineNo, path, "%s value %q %s", path, value, problem)
+		}
+
+		frame.structure = rule.Structure
+		frame.checked = true
+	}
+
+	for len(stack) > 0 {
+		finish(stack[len(stack)-1])
+		stack = stack[:len(stack)-1]
+	}
+
+	// Missing substructures are found at the end of a structure, so put
+	// issues back in line order with those about the whole file last.
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Line, issues[j].Line
+		return a != 0 && (b == 0 || a < b)
+	})
+
+	return issues, nil
+}
+
+// readLines splits r into lines ended by any of CR, LF or CR LF.
+func readLines(r io.Reader) ([][]byte, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), 1<<20)
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			if data[i] == '\r' {
+				if i+1 < len(data) {
+					if data[i+1] == '\n' {
+						return i + 2, data[:i], nil
+					}
+					return i + 1, data[:i], nil
+				}
+				if !atEOF {
+					return 0, nil, nil
+				}
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	var lines [][]byte
+	for s.Scan() {
+		lines = append(lines, append([]byte(nil), s.Bytes()...))
+	}
+	return lines, s.Err()
+}
+
+// headerVersion returns the value of HEAD.GEDC.VERS.
+func headerVersion(lines [][]byte) string {
+	var path []string
+	for _, raw := range lines {
+		s := &scanner{}
+		s.reset()
+		if _, err := s.nextTag(append(raw, '\n')); err != nil {
+			continue
+		}
+		if s.level > len(path) {
+			continue
+		}
+		path = append(path[:s.level], string(s.tag))
+		if strings.Join(path, ".") == "HEAD.GEDC.VERS" {
+			return strings.TrimSpace(string(s.value))
+		}
+		if s.level == 0 && string(s.tag) != "HEAD" {
+			break
+		}
+	}
+	return ""
+}
+
+// sortedTags returns the tags of rules in order, so that issues are reported
+// consistently.
+func sortedTags(rules map[string]Rule) []string {
+	tags := make([]string, 0, len(rules))
+	for tag := range rules {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,72 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func lintFile(t *testing.T, name string, gr *Grammar) string {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Could not read %s: %v", name, err)
+	}
+	issues, err := Lint(bytes.NewReader(data), gr)
+	if err != nil {
+		t.Fatalf("Linting %s gave error %v, expected no error", name, err)
+	}
+	var lines []string
+	for _, i := range issues {
+		lines = append(lines, i.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestLint551(t *testing.T) {
+	expected := []string{
+		"line 1: HEAD is missing required SOUR",
+		"line 9: SEX occurs 2 times in INDI, at most 1 allowed",
+		"line 11: INDI.SEX value \"Q\" is not one of M, F, U",
+		"line 15: QUAY is not allowed in INDI.BIRT",
+		"line 23: INDI.FAMC.PEDI value \"unknown\" is not one of ADOPTED, BIRTH, FOSTER, SEALING",
+		"line 25: INDI.SOUR.QUAY value \"5\" is not one of 0, 1, 2, 3",
+		"line 26: INDI.OCCU value \"A very long occupation that goes on and on well beyond the ninety character limit of the specification\" is longer than 90 characters",
+		"line 29: FAM.CHIL value \"I2\" is not a pointer",
+		"the file is missing required TRLR",
+	}
+	stringTestCases{
+		{"Issues", strings.Join(expected, "\n"), lintFile(t, "testdata/lint.ged", nil)},
+	}.run(t)
+
+	boolTestCases{
+		{"CONC under PAGE allowed", false, strings.Contains(lintFile(t, "testdata/allged.ged", nil), "CONC is not allowed in INDI.NAME.SOUR.PAGE")},
+	}.run(t)
+
+	values := make(map[string]string)
+	for _, r := range Grammar551.Structures["NAME_PIECES"] {
+		values[r.Tag] = r.Value
+	}
+	stringTestCases{
+		{"SURN value type", "nameSurname", values["SURN"]},
+		{"NSFX value type", "nameSuffix", values["NSFX"]},
+	}.run(t)
+}
+
+func TestLint70(t *testing.T) {
+	expected := []string{
+		"line 16: INDI.FAMC.PEDI value \"birth\" is not one of ADOPTED, BIRTH, FOSTER, SEALING, OTHER",
+		"line 25: CONC is not allowed in SNOTE",
+	}
+	stringTestCases{
+		{"Issues", strings.Join(expected, "\n"), lintFile(t, "testdata/lint70.ged", nil)},
+	}.run(t)
+
+	boolTestCases{
+		{"5.5.1 grammar requiring CHAR", true, strings.Contains(lintFile(t, "testdata/lint70.ged", Grammar551), "HEAD is missing required CHAR")},
+	}.run(t)
+}
@@ -3,6 +3,7 @@ package gedcom
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"testing"
@@ -56,6 +57,19 @@ func (testCases boolTestCases) run(t *testing.T) {
 	}
 }
 
+func decodeFile(t *testing.T, name string) *Gedcom {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Could not read %s: %v", name, err)
+	}
+
+	g, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("Result of decoding %s gave error %v, expected no error", name, err)
+	}
+	return g
+}
+
 func TestMain(m *testing.M) {
 
 	d := NewDecoder(bytes.NewReader(data))
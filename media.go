@@ -0,0 +1,161 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A MediaResolver locates the files referenced by multimedia objects on the
+// local filesystem. Relative names are resolved against the directory
+// containing the GEDCOM file.
+type MediaResolver struct {
+	Dir string
+}
+
+// NewMediaResolver returns a resolver for media referenced by the GEDCOM file
+// at gedPath.
+func NewMediaResolver(gedPath string) *MediaResolver {
+	return &MediaResolver{Dir: filepath.Dir(gedPath)}
+}
+
+// Resolve returns the local path of the file described by f. An error is
+// returned if the file cannot be found.
+func (r *MediaResolver) Resolve(f *FileRecord) (string, error) {
+	if f == nil || f.Name == "" {
+		return "", fmt.Errorf("media file has no name")
+	}
+
+	name := f.Name
+	if strings.HasPrefix(name, "file://") {
+		name = strings.TrimPrefix(name, "file://")
+	} else if strings.Contains(name, "://") {
+		return "", fmt.Errorf("media file %q is not a local file", f.Name)
+	}
+
+	// Files written on Windows use backslashes and may carry a drive letter
+	name = strings.Replace(name, "\\", "/", -1)
+	if len(name) > 1 && name[1] == ':' {
+		name = name[2:]
+	}
+	name = filepath.FromSlash(name)
+
+	candidates := []string{}
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		candidates = append(candidates, filepath.Join(r.Dir, name))
+	}
+	candidates = append(candidates, filepath.Join(r.Dir, filepath.Base(name)))
+
+	for _, c := range candidates {
+		if fi, err := os.Stat(c); err == nil && !fi.IsDir() {
+			return c, nil
+		}
+	}
+
+	return "", fmt.Errorf("media file %q not found", f.Name)
+}
+
+// Missing returns all files referenced by multimedia objects in g that cannot
+// be resolved to a local file.
+func (r *MediaResolver) Missing(g *Gedcom) []*FileRecord {
+	var missing []*FileRecord
+	for _, o := range g.objects() {
+		for _, f := range o.File {
+			if _, err := r.Resolve(f); err != nil {
+				missing = append(missing, f)
+			}
+		}
+	}
+	return missing
+}
+
+// objects returns every multimedia object in g, including those embedded in
+// other records, without duplicates.
+func (g *Gedcom) objects() []*ObjectRecord {
+	var objs []*ObjectRecord
+	seen := make(map[*ObjectRecord]bool)
+
+	add := func(rs []*ObjectRecord) {
+		for _, o := range rs {
+			if o != nil && !seen[o] {
+				seen[o] = true
+				objs = append(objs, o)
+			}
+		}
+	}
+	addCitations := func(cs []*CitationRecord) {
+		for _, c := range cs {
+			add(c.Object)
+		}
+	}
+	addEvents := func(es []*EventRecord) {
+		for _, e := range es {
+			add(e.Object)
+			addCitations(e.Citation)
+		}
+	}
+
+	add(g.Object)
+	for _, i := range g.Individual {
+		add(i.Object)
+		if i.Photo != nil {
+			add([]*ObjectRecord{i.Photo})
+		}
+		addCitations(i.Citation)
+		addEvents(i.Event)
+		addEvents(i.Attribute)
+		for _, n := range i.Name {
+			addCitations(n.Citation)
+		}
+	}
+	for _, f := range g.Family {
+		add(f.Object)
+		addCitations(f.Citation)
+		addEvents(f.Event)
+	}
+	for _, s := range g.Source {
+		add(s.Object)
+	}
+
+	return objs
+}
+
+// decodeBlob decodes GEDCOM 5.5 BLOB data. Each character carries six bits,
+// encoded as '.' and '/' for 0 and 1, '0'-'9' for 2-11, 'A'-'Z' for 12-37
+// and 'a'-'z' for 38-63. Every four characters yield three bytes.
+func decodeBlob(encoded []byte) []byte {
+	var out []byte
+	var acc uint
+	var bits uint
+
+	for _, c := range encoded {
+		var v uint
+		switch {
+		case c >= '.' && c <= '9':
+			v = uint(c - '.')
+		case c >= 'A' && c <= 'Z':
+			v = uint(c-'A') + 12
+		case c >= 'a' && c <= 'z':
+			v = uint(c-'a') + 38
+		default:
+			continue
+		}
+		acc = acc<<6 | v
+		bits += 6
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+			acc &= 1<<bits - 1
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,66 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMultimedia(t *testing.T) {
+
+	m := decodeFile(t, "testdata/media.ged")
+
+	intTestCases{
+		{"Object list length was [%d]", 3, len(m.Object)},
+		{"Object 0 file count was [%d]", 2, len(m.Object[0].File)},
+		{"Object 2 file count was [%d]", 0, len(m.Object[2].File)},
+	}.run(t)
+
+	stringTestCases{
+		{"Object 0 file 0 form", "jpg", m.Object[0].File[0].Form},
+		{"Object 0 file 0 media type", "photo", m.Object[0].File[0].MediaType},
+		{"Object 0 file 0 title", "Portrait", m.Object[0].File[0].Title},
+		{"Object 0 file 1 media type", "document", m.Object[0].File[1].MediaType},
+		{"Object 1 file 0 form", "image/png", m.Object[1].File[0].Form},
+		{"Object 1 file 0 media type", "PHOTO", m.Object[1].File[0].MediaType},
+		{"Object 2 form", "bmp", m.Object[2].Form},
+		{"Object 2 title", "Embedded picture", m.Object[2].Title},
+		{"Object 2 blob", "GEDCOM blob", string(m.Object[2].Blob)},
+		{"Embedded object file form", "jpg", m.Individual[0].Object[0].File[0].Form},
+		{"Embedded object file title", "Jane", m.Individual[0].Object[0].File[0].Title},
+	}.run(t)
+}
+
+func TestMediaResolver(t *testing.T) {
+
+	m := decodeFile(t, "testdata/media.ged")
+	r := NewMediaResolver("testdata/media.ged")
+
+	path, err := r.Resolve(m.Object[0].File[0])
+	if err != nil {
+		t.Fatalf("Resolve gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Resolved relative path", filepath.Join("testdata", "media", "portrait.jpg"), path},
+	}.run(t)
+
+	if _, err := r.Resolve(m.Object[0].File[1]); err == nil {
+		t.Errorf("Resolve of missing file gave no error, expected error")
+	}
+
+	missing := r.Missing(m)
+
+	intTestCases{
+		{"Missing file count was [%d]", 3, len(missing)},
+	}.run(t)
+
+	stringTestCases{
+		{"Missing file 0", `C:\Archive\scans\missing.tif`, missing[0].Name},
+		{"Missing file 1", "https://example.com/image.png", missing[1].Name},
+		{"Missing file 2", "portrait.jpg", missing[2].Name},
+	}.run(t)
+}
@@ -0,0 +1,412 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeIndividuals merges drop into keep. Names, events and attributes that
+// keep lacks are added to it, as are citations, notes, objects, associations
+// and family links, and empty fields of keep are filled from drop. Every
+// reference to drop is changed to refer to keep and drop is removed from
+// g.Individual. The returned log describes the changes and can undo them.
+func (g *Gedcom) MergeIndividuals(keep *IndividualRecord, drop *IndividualRecord) (*ChangeLog, error) {
+	if err := checkMerge("individual", keep, drop, g.Individual); err != nil {
+		return nil, err
+	}
+	ks, ds := strings.ToUpper(keep.Sex), strings.ToUpper(drop.Sex)
+	if (ks == "M" || ks == "F") && (ds == "M" || ds == "F") && ks != ds {
+		return nil, fmt.Errorf("individuals %s and %s are of different sexes", keep.Xref, drop.Xref)
+	}
+
+	log := newChangeLog(g)
+	g.rewire(drop, keep, log)
+
+	log.save(keep)
+	if ks != "M" && ks != "F" && drop.Sex != "" {
+		keep.Sex = drop.Sex
+	}
+	keep.Name = mergeNames(log, keep.Name, drop.Name)
+	keep.Event = mergeEvents(log, keep.Event, drop.Event)
+	keep.Attribute = mergeEvents(log, keep.Attribute, drop.Attribute)
+	keep.Parents = mergeFamilyLinks(log, keep.Parents, drop.Parents)
+	keep.Family = mergeFamilyLinks(log, keep.Family, drop.Family)
+	mergeFields(keep, drop, "Xref", "Sex", "Name", "Event", "Attribute", "Parents", "Family")
+
+	for _, l := range keep.Parents {
+		if l.Family != nil {
+			dedupeChildren(log, l.Family)
+		}
+	}
+
+	log.save(g)
+	individuals := make([]*IndividualRecord, 0, len(g.Individual)-1)
+	for _, i := range g.Individual {
+		if i != drop {
+			individuals = append(individuals, i)
+		}
+	}
+	g.Individual = individuals
+
+	log.note("merged individual %s into %s", drop.Xref, keep.Xref)
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return log, nil
+}
+
+// MergeFamilies merges drop into keep. The families must not have different
+// husbands or different wives. Children, events, citations, notes and
+// objects that keep lacks are added to it, every reference to drop is
+// changed to refer to keep and drop is removed from g.Family.
+func (g *Gedcom) MergeFamilies(keep *FamilyRecord, drop *FamilyRecord) (*ChangeLog, error) {
+	if err := checkMerge("family", keep, drop, g.Family); err != nil {
+		return nil, err
+	}
+	if keep.Husband != nil && drop.Husband != nil && keep.Husband != drop.Husband {
+		return nil, fmt.Errorf("families %s and %s have different husbands", keep.Xref, drop.Xref)
+	}
+	if keep.Wife != nil && drop.Wife != nil && keep.Wife != drop.Wife {
+		return nil, fmt.Errorf("families %s and %s have different wives", keep.Xref, drop.Xref)
+	}
+
+	log := newChangeLog(g)
+	g.rewire(drop, keep, log)
+
+	log.save(keep)
+	keep.Child = append(append([]*ChildRecord(nil), keep.Child...), drop.Child...)
+	keep.Event = mergeEvents(log, keep.Event, drop.Event)
+	mergeFields(keep, drop, "Xref", "Child", "Event")
+	dedupeChildren(log, keep)
+
+	for _, p := range append([]*IndividualRecord{keep.Husband, keep.Wife}, keep.Children()...) {
+		if p == nil {
+			continue
+		}
+		log.save(p)
+		p.Parents = mergeFamilyLinks(log, p.Parents, nil)
+		p.Family = mergeFamilyLinks(log, p.Family, nil)
+	}
+
+	log.save(g)
+	families := make([]*FamilyRecord, 0, len(g.Family)-1)
+	for _, f := range g.Family {
+		if f != drop {
+			families = append(families, f)
+		}
+	}
+	g.Family = families
+
+	log.note("merged family %s into %s", drop.Xref, keep.Xref)
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return log, nil
+}
+
+// MergeSources merges drop into keep, filling empty fields of keep from drop,
+// changing every citation of drop to cite keep and removing drop from
+// g.Source.
+func (g *Gedcom) MergeSources(keep *SourceRecord, drop *SourceRecord) (*ChangeLog, error) {
+	return g.mergeRecord("source", "Source", keep, drop)
+}
+
+// MergeNotes merges drop into keep, appending the text of drop if it differs,
+// changing every reference to drop to refer to keep and removing drop from
+// g.Note.
+func (g *Gedcom) MergeNotes(keep *NoteRecord, drop *NoteRecord) (*ChangeLog, error) {
+	if err := checkMerge("note", keep, drop, g.Note); err != nil {
+		return nil, err
+	}
+
+	log := newChangeLog(g)
+	g.rewire(drop, keep, log)
+
+	log.save(keep)
+	switch {
+	case keep.Note == "":
+		keep.Note = drop.Note
+	case drop.Note != "" && drop.Note != keep.Note:
+		keep.Note = keep.Note + "\n" + drop.Note
+	}
+	mergeFields(keep, drop, "Xref", "Note")
+
+	log.save(g)
+	notes := make([]*NoteRecord, 0, len(g.Note))
+	for _, n := range g.Note {
+		if n != drop {
+			notes = append(notes, n)
+		}
+	}
+	g.Note = notes
+
+	log.note("merged note %s into %s", drop.Xref, keep.Xref)
+	g.Reindex()
+	return log, nil
+}
+
+// mergeRecord merges drop into keep, records of the kind held in the list
+// field of g, by filling the empty fields of keep from drop, changing every
+// reference to drop to refer to keep and removing drop from the list.
+func (g *Gedcom) mergeRecord(kind string, field string, keep interface{}, drop interface{}) (*ChangeLog, error) {
+	list := reflect.ValueOf(g).Elem().FieldByName(field)
+	if err := checkMerge(kind, keep, drop, list.Interface()); err != nil {
+		return nil, err
+	}
+
+	log := newChangeLog(g)
+	g.rewire(drop, keep, log)
+
+	log.save(keep)
+	mergeFields(keep, drop, "Xref")
+
+	log.save(g)
+	kept := reflect.MakeSlice(list.Type(), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if list.Index(i).Pointer() != reflect.ValueOf(drop).Pointer() {
+			kept = reflect.Append(kept, list.Index(i))
+		}
+	}
+	list.Set(kept)
+
+	log.note("merged %s %s into %s", kind, recordXref(reflect.ValueOf(drop)), recordXref(reflect.ValueOf(keep)))
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return log, nil
+}
+
+// checkMerge checks that keep and drop are distinct records and that drop is
+// one of records.
+func checkMerge(kind string, keep interface{}, drop interface{}, records interface{}) error {
+	kv, dv := reflect.ValueOf(keep), reflect.ValueOf(drop)
+	if kv.IsNil() || dv.IsNil() {
+		return fmt.Errorf("cannot merge a nil %s", kind)
+	}
+	if kv.Pointer() == dv.Pointer() {
+		return fmt.Errorf("cannot merge %s %s into itself", kind, recordXref(kv))
+	}
+	rv := reflect.ValueOf(records)
+	for i := 0; i < rv.Len(); i++ {
+		if rv.Index(i).Pointer() == dv.Pointer() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s is not in the file", kind, recordXref(dv))
+}
+
+// rewire changes every reference to the record from, other than those in the
+// record lists of g itself, to refer to the record to.
+func (g *Gedcom) rewire(from interface{}, to interface{}, log *ChangeLog) {
+	fv, tv := reflect.ValueOf(from), reflect.ValueOf(to)
+	seen := make(map[interface{}]bool)
+
+	var visitStruct func(s reflect.Value, path string)
+	visit := func(v reflect.Value, path string) {
+		switch {
+		case v.Kind() == reflect.Ptr && v.Type() == fv.Type() && v.Pointer() == fv.Pointer():
+			log.set(v, tv)
+			log.note("%s changed from %s to %s", path, recordXref(fv), recordXref(tv))
+		case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct && v.Elem().Type().PkgPath() == pkgPath:
+			if _, ok := v.Interface().(*Place); ok || seen[v.Interface()] {
+				return
+			}
+			seen[v.Interface()] = true
+			if x := recordXref(v); x != "" {
+				path = x
+			}
+			visitStruct(v.Elem(), path)
+		case v.Kind() == reflect.Struct && v.Type().PkgPath() == pkgPath:
+			visitStruct(v, path)
+		}
+	}
+	visitStruct = func(s reflect.Value, path string) {
+		for i := 0; i < s.NumField(); i++ {
+			f := s.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			fp := path + "." + s.Type().Field(i).Name
+			if f.Kind() == reflect.Slice {
+				for j := 0; j < f.Len(); j++ {
+					visit(f.Index(j), fp)
+				}
+				continue
+			}
+			visit(f, fp)
+		}
+	}
+
+	s := reflect.ValueOf(g).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Ptr:
+			visit(f, s.Type().Field(i).Name)
+		case reflect.Slice:
+			for j := 0; j < f.Len(); j++ {
+				if e := f.Index(j); e.Kind() == reflect.Ptr && e.Pointer() != fv.Pointer() {
+					visit(e, s.Type().Field(i).Name)
+				}
+			}
+		}
+	}
+}
+
+// recordXref returns the Xref of the record pointed to by v, if it has one.
+func recordXref(v reflect.Value) string {
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	if x := v.Elem().FieldByName("Xref"); x.IsValid() && x.Kind() == reflect.String {
+		return x.String()
+	}
+	return ""
+}
+
+// mergeFields fills the empty string and pointer fields of the struct pointed
+// to by keep from drop and appends to its slices the elements of drop's that
+// they lack. Fields named in skip are left alone. Slices are replaced, not
+// modified, so that a ChangeLog can restore them.
+func mergeFields(keep interface{}, drop interface{}, skip ...string) {
+	kv, dv := reflect.ValueOf(keep).Elem(), reflect.ValueOf(drop).Elem()
+fields:
+	for i := 0; i < kv.NumField(); i++ {
+		name := kv.Type().Field(i).Name
+		for _, s := range skip {
+			if s == name {
+				continue fields
+			}
+		}
+		k, d := kv.Field(i), dv.Field(i)
+		if !k.CanSet() {
+			continue
+		}
+		switch k.Kind() {
+		case reflect.String:
+			if k.String() == "" {
+				k.SetString(d.String())
+			}
+		case reflect.Ptr:
+			if k.IsNil() {
+				k.Set(d)
+			}
+		case reflect.Slice:
+			if d.Len() == 0 || !k.Type().Elem().Comparable() {
+				continue
+			}
+			merged := reflect.MakeSlice(k.Type(), 0, k.Len()+d.Len())
+			merged = reflect.AppendSlice(merged, k)
+		elements:
+			for j := 0; j < d.Len(); j++ {
+				e := d.Index(j)
+				for n := 0; n < k.Len(); n++ {
+					if k.Index(n).Interface() == e.Interface() {
+						continue elements
+					}
+				}
+				merged = reflect.Append(merged, e)
+			}
+			k.Set(merged)
+		}
+	}
+}
+
+// mergeNames adds the names in drop that differ from those in keep. The
+// citations and notes of a name in both are combined.
+func mergeNames(log *ChangeLog, keep []*NameRecord, drop []*NameRecord) []*NameRecord {
+	merged := append([]*NameRecord(nil), keep...)
+names:
+	for _, d := range drop {
+		for _, k := range keep {
+			if foldName(k.Name) == foldName(d.Name) {
+				if k != d {
+					log.save(k)
+					mergeFields(k, d, "Name")
+				}
+				continue names
+			}
+		}
+		merged = append(merged, d)
+	}
+	return merged
+}
+
+// mergeEvents adds the events in drop that are not also in keep, where events
+// with the same tag, value and date and no conflicting place are taken to be
+// the same. The
+// citations, notes and other details of an event in both are combined.
+func mergeEvents(log *ChangeLog, keep []*EventRecord, drop []*EventRecord) []*EventRecord {
+	same := func(a *EventRecord, b *EventRecord) bool {
+		return a.Tag == b.Tag &&
+			strings.EqualFold(strings.TrimSpace(a.Value), strings.TrimSpace(b.Value)) &&
+			strings.EqualFold(strings.TrimSpace(a.Date), strings.TrimSpace(b.Date)) &&
+			(a.Place.Name == "" || b.Place.Name == "" || foldName(a.Place.Name) == foldName(b.Place.Name))
+	}
+
+	merged := append([]*EventRecord(nil), keep...)
+events:
+	for _, d := range drop {
+		for _, k := range keep {
+			if same(k, d) {
+				if k != d {
+					log.save(k)
+					mergeFields(k, d, "Tag")
+					if k.Place.Name == "" {
+						k.Place = d.Place
+					}
+				}
+				continue events
+			}
+		}
+		merged = append(merged, d)
+	}
+	return merged
+}
+
+// mergeFamilyLinks combines two lists of family links, keeping one link to
+// each family.
+func mergeFamilyLinks(log *ChangeLog, keep []*FamilyLinkRecord, drop []*FamilyLinkRecord) []*FamilyLinkRecord {
+	var merged []*FamilyLinkRecord
+links:
+	for _, l := range append(append([]*FamilyLinkRecord(nil), keep...), drop...) {
+		for _, m := range merged {
+			if m.Family == l.Family {
+				if m != l {
+					log.save(m)
+					mergeFields(m, l, "Family")
+				}
+				continue links
+			}
+		}
+		merged = append(merged, l)
+	}
+	return merged
+}
+
+// dedupeChildren removes repeated children from family f, as arise when two
+// of its children are merged.
+func dedupeChildren(log *ChangeLog, f *FamilyRecord) {
+	var children []*ChildRecord
+	changed := false
+children:
+	for _, c := range f.Child {
+		for _, d := range children {
+			if d.Person == c.Person {
+				changed = true
+				continue children
+			}
+		}
+		children = append(children, c)
+	}
+	if changed {
+		log.save(f)
+		f.Child = children
+	}
+}
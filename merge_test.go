@@ -0,0 +1,178 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func family(t *testing.T, mg *Gedcom, xref string) *FamilyRecord {
+	for _, f := range mg.Family {
+		if f.Xref == xref {
+			return f
+		}
+	}
+	t.Fatalf("Family %s not found", xref)
+	return nil
+}
+
+func xrefs(people []*IndividualRecord) string {
+	var s []string
+	for _, p := range people {
+		s = append(s, p.Xref)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestMergeIndividuals(t *testing.T) {
+	mg := decodeFile(t, "testdata/merge.ged")
+	i1, i2 := person(t, mg, "I1"), person(t, mg, "I2")
+
+	log, err := mg.MergeIndividuals(i1, i2)
+	if err != nil {
+		t.Fatalf("MergeIndividuals gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Individual count after merge was [%d]", 5, len(mg.Individual)},
+		{"Name count after merge was [%d]", 2, len(i1.Name)},
+		{"Name note count after merge was [%d]", 1, len(i1.Name[0].Note)},
+		{"Event count after merge was [%d]", 2, len(i1.Event)},
+		{"Birth citation count after merge was [%d]", 1, len(i1.Event[0].Citation)},
+		{"Parent family count after merge was [%d]", 2, len(i1.Parents)},
+		{"Note count after merge was [%d]", 2, len(i1.Note)},
+	}.run(t)
+
+	stringTestCases{
+		{"Birth place after merge", "Springfield", i1.Event[0].Place.Name},
+		{"F2 children after merge", "I1", xrefs(family(t, mg, "F2").Children())},
+		{"I5 children after merge", "I1 I6", xrefs(person(t, mg, "I5").Children())},
+		{"Changes", "F2.Child.Person changed from I2 to I1\nmerged individual I2 into I1", strings.Join(log.Changes, "\n")},
+	}.run(t)
+
+	log.Undo()
+
+	intTestCases{
+		{"Individual count after undo was [%d]", 6, len(mg.Individual)},
+		{"Name count after undo was [%d]", 1, len(i1.Name)},
+		{"Name note count after undo was [%d]", 0, len(i1.Name[0].Note)},
+		{"Event count after undo was [%d]", 1, len(i1.Event)},
+		{"Parent family count after undo was [%d]", 1, len(i1.Parents)},
+	}.run(t)
+
+	stringTestCases{
+		{"Birth place after undo", "", i1.Event[0].Place.Name},
+		{"F2 children after undo", "I2", xrefs(family(t, mg, "F2").Children())},
+		{"I5 children after undo", "I1 I6 I2", xrefs(person(t, mg, "I5").Children())},
+	}.run(t)
+
+	if _, err := mg.MergeIndividuals(i1, person(t, mg, "I4")); err == nil {
+		t.Errorf("Merging a man and a woman gave no error")
+	}
+	if _, err := mg.MergeIndividuals(i1, i1); err == nil {
+		t.Errorf("Merging an individual into itself gave no error")
+	}
+}
+
+func TestMergeFamiliesPlaces(t *testing.T) {
+	pg := decodeFile(t, "testdata/places.ged")
+	f1 := family(t, pg, "F1")
+	f2, err := pg.AddFamily(person(t, pg, "I1"), nil)
+	if err != nil {
+		t.Fatalf("AddFamily gave error %v, expected no error", err)
+	}
+	if _, err := pg.AddEvent(f2, "MARR", "1875", "Salem, Essex, Massachusetts, USA"); err != nil {
+		t.Fatalf("AddEvent gave error %v, expected no error", err)
+	}
+
+	log, err := pg.MergeFamilies(f1, f2)
+	if err != nil {
+		t.Fatalf("MergeFamilies gave error %v, expected no error", err)
+	}
+	intTestCases{
+		{"Salem event count after merge was [%d]", 3, len(pg.Place[0].Event)},
+	}.run(t)
+
+	log.Undo()
+	intTestCases{
+		{"Salem event count after undo was [%d]", 4, len(pg.Place[0].Event)},
+	}.run(t)
+}
+
+func TestMergeFamilies(t *testing.T) {
+	mg := decodeFile(t, "testdata/merge.ged")
+	f1, f2 := family(t, mg, "F1"), family(t, mg, "F2")
+
+	log, err := mg.MergeFamilies(f1, f2)
+	if err != nil {
+		t.Fatalf("MergeFamilies gave error %v, expected no error", err)
+	}
+
+	i5 := person(t, mg, "I5")
+	intTestCases{
+		{"Family count after merge was [%d]", 2, len(mg.Family)},
+		{"Event count after merge was [%d]", 1, len(f1.Event)},
+		{"Spouse link count of I5 after merge was [%d]", 1, len(i5.Family)},
+	}.run(t)
+	stringTestCases{
+		{"F1 children after merge", "I1 I6 I2", xrefs(f1.Children())},
+		{"I2 father after merge", "I5", person(t, mg, "I2").Father().Xref},
+		{"I2 mother after merge", "I4", person(t, mg, "I2").Mother().Xref},
+	}.run(t)
+
+	log.Undo()
+
+	intTestCases{
+		{"Family count after undo was [%d]", 3, len(mg.Family)},
+		{"Event count after undo was [%d]", 0, len(f1.Event)},
+		{"Spouse link count of I5 after undo was [%d]", 2, len(i5.Family)},
+	}.run(t)
+	boolTestCases{
+		{"I2 has a mother after undo", false, person(t, mg, "I2").Mother() != nil},
+	}.run(t)
+
+	if _, err := mg.MergeFamilies(f1, family(t, mg, "F3")); err == nil {
+		t.Errorf("Merging families with different wives gave no error")
+	}
+}
+
+func TestMergeSourcesAndNotes(t *testing.T) {
+	mg := decodeFile(t, "testdata/merge.ged")
+	s1, s2 := mg.Source[0], mg.Source[1]
+
+	log, err := mg.MergeSources(s1, s2)
+	if err != nil {
+		t.Fatalf("MergeSources gave error %v, expected no error", err)
+	}
+	intTestCases{
+		{"Source count after merge was [%d]", 1, len(mg.Source)},
+		{"Citations of S1 after merge was [%d]", 2, len(mg.CitationsOf(s1))},
+	}.run(t)
+	stringTestCases{
+		{"Author after merge", "Vicar", s1.Author},
+	}.run(t)
+
+	log.Undo()
+	intTestCases{
+		{"Source count after undo was [%d]", 2, len(mg.Source)},
+		{"Citations of S1 after undo was [%d]", 1, len(mg.CitationsOf(s1))},
+	}.run(t)
+	stringTestCases{
+		{"Author after undo", "", s1.Author},
+	}.run(t)
+
+	n1, n2 := mg.Note[0], mg.Note[1]
+	if _, err := mg.MergeNotes(n1, n2); err != nil {
+		t.Fatalf("MergeNotes gave error %v, expected no error", err)
+	}
+	intTestCases{
+		{"Note count after merge was [%d]", 1, len(mg.Note)},
+		{"References to N1 after merge was [%d]", 2, len(mg.ReferencesTo(n1))},
+	}.run(t)
+	stringTestCases{
+		{"Note text after merge", "Farmer\nFarmer\nLater a miller", n1.Note},
+	}.run(t)
+}
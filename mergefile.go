@@ -0,0 +1,337 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultAutoMergeScore is the duplicate score at which Merge combines
+// individuals when no other minimum is given.
+const DefaultAutoMergeScore = 80
+
+// MergeOptions controls how Merge combines two files.
+type MergeOptions struct {
+	// MergeDuplicates merges each individual of the second file into the
+	// best matching individual of the first, when their score from
+	// CompareIndividuals is at least DuplicateScore. Families of the second
+	// file with the same husband and wife as one of the first are then
+	// merged too.
+	MergeDuplicates bool
+	DuplicateScore  int // zero means DefaultAutoMergeScore
+}
+
+// MergeReport describes how Merge combined two files.
+type MergeReport struct {
+	// Renumbered maps the xrefs of records from the second file that were
+	// already used in the first to the xrefs they were given.
+	Renumbered map[string]string
+
+	// Merged maps the xrefs of records from the second file that were
+	// merged into records of the first, as renumbered, to the xrefs of those
+	// records.
+	Merged map[string]string
+
+	// Changes describes each change made in merging, in order.
+	Changes []string
+}
+
+// String summarises the report, one line per renumbered or merged record.
+func (r *MergeReport) String() string {
+	var lines []string
+	for _, x := range sortedKeys(r.Renumbered) {
+		lines = append(lines, fmt.Sprintf("renumbered %s as %s", x, r.Renumbered[x]))
+	}
+	for _, x := range sortedKeys(r.Merged) {
+		lines = append(lines, fmt.Sprintf("merged %s into %s", x, r.Merged[x]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Merge combines a and b into a new Gedcom, leaving both unchanged. Records
+// of b whose xrefs are already used in a are renumbered. Sources,
+// repositories, notes and submitters of b with the same content as one of a
+// are merged into it. The header, submission and trailer are those of a,
+// or of b where a has none.
+func Merge(a *Gedcom, b *Gedcom, opts *MergeOptions) (*Gedcom, *MergeReport, error) {
+	if a == nil || b == nil {
+		return nil, nil, fmt.Errorf("cannot merge a nil Gedcom")
+	}
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+
+	ca, cb := a.Copy(), b.Copy()
+	report := &MergeReport{Renumbered: make(map[string]string), Merged: make(map[string]string)}
+
+	alloc := newXrefAllocator()
+	inA := make(map[string]bool)
+	for _, rec := range ca.xrefRecords() {
+		x := rec.Elem().FieldByName("Xref").String()
+		inA[x] = true
+		alloc.use(x)
+	}
+	for _, rec := range cb.xrefRecords() {
+		alloc.use(rec.Elem().FieldByName("Xref").String())
+	}
+
+	for _, rec := range cb.xrefRecords() {
+		x := rec.Elem().FieldByName("Xref").String()
+		if x == "" || !inA[x] {
+			continue
+		}
+		nx := alloc.allocate(xrefPrefix(rec, x))
+		setXref(rec, nx)
+		report.Renumbered[x] = nx
+		report.Changes = append(report.Changes, fmt.Sprintf("renumbered %s as %s", x, nx))
+	}
+	for _, s := range cb.Source {
+		s.Repository = renumberPointers(s.Repository, report.Renumbered)
+		s.Submitter = renumberPointers(s.Submitter, report.Renumbered)
+	}
+
+	g := &Gedcom{
+		Header:     ca.Header,
+		Submission: ca.Submission,
+		Trailer:    ca.Trailer,
+		Submitter:  append(ca.Submitter, cb.Submitter...),
+		Family:     append(ca.Family, cb.Family...),
+		Individual: append(ca.Individual, cb.Individual...),
+		Object:     append(ca.Object, cb.Object...),
+		Repository: append(ca.Repository, cb.Repository...),
+		Source:     append(ca.Source, cb.Source...),
+		Note:       append(ca.Note, cb.Note...),
+	}
+	if g.Header == nil {
+		g.Header = cb.Header
+	}
+	if g.Submission == nil {
+		g.Submission = cb.Submission
+	}
+	if g.Trailer == nil {
+		g.Trailer = cb.Trailer
+	}
+
+	merged := func(drop interface{}, keep string, log *ChangeLog) {
+		report.Merged[recordXref(reflect.ValueOf(drop))] = keep
+		report.Changes = append(report.Changes, log.Changes...)
+	}
+
+	// Repositories go first so that sources citing the same repository
+	// agree once they are merged.
+	var keepKeys, dropKeys []string
+	for _, r := range ca.Repository {
+		keepKeys = append(keepKeys, repositoryKey(r))
+	}
+	for _, r := range cb.Repository {
+		dropKeys = append(dropKeys, repositoryKey(r))
+	}
+	for _, p := range pairByKey(keepKeys, dropKeys) {
+		keep, drop := ca.Repository[p.keep], cb.Repository[p.drop]
+		if log, err := g.mergeRecord("repository", "Repository", keep, drop); err == nil {
+			merged(drop, keep.Xref, log)
+			for _, s := range g.Source {
+				s.Repository = renumberPointers(s.Repository, map[string]string{drop.Xref: keep.Xref})
+			}
+		}
+	}
+
+	keepKeys, dropKeys = nil, nil
+	for _, n := range ca.Note {
+		keepKeys = append(keepKeys, contentKey(n.Note))
+	}
+	for _, n := range cb.Note {
+		dropKeys = append(dropKeys, contentKey(n.Note))
+	}
+	for _, p := range pairByKey(keepKeys, dropKeys) {
+		keep, drop := ca.Note[p.keep], cb.Note[p.drop]
+		if log, err := g.MergeNotes(keep, drop); err == nil {
+			merged(drop, keep.Xref, log)
+		}
+	}
+
+	keepKeys, dropKeys = nil, nil
+	for _, u := range ca.Submitter {
+		keepKeys = append(keepKeys, contentKey(u.Name))
+	}
+	for _, u := range cb.Submitter {
+		dropKeys = append(dropKeys, contentKey(u.Name))
+	}
+	for _, p := range pairByKey(keepKeys, dropKeys) {
+		keep, drop := ca.Submitter[p.keep], cb.Submitter[p.drop]
+		if log, err := g.mergeRecord("submitter", "Submitter", keep, drop); err == nil {
+			merged(drop, keep.Xref, log)
+			for _, s := range g.Source {
+				s.Submitter = renumberPointers(s.Submitter, map[string]string{drop.Xref: keep.Xref})
+			}
+		}
+	}
+
+	keepKeys, dropKeys = nil, nil
+	for _, s := range ca.Source {
+		keepKeys = append(keepKeys, contentKey(s.Title, s.Author, s.Publication))
+	}
+	for _, s := range cb.Source {
+		dropKeys = append(dropKeys, contentKey(s.Title, s.Author, s.Publication))
+	}
+	for _, p := range pairByKey(keepKeys, dropKeys) {
+		keep, drop := ca.Source[p.keep], cb.Source[p.drop]
+		if log, err := g.mergeRecord("source", "Source", keep, drop); err == nil {
+			merged(drop, keep.Xref, log)
+		}
+	}
+
+	if opts.MergeDuplicates {
+		mergeDuplicates(g, ca, cb, opts, merged)
+	}
+
+	g.Place = indexPlaces(g)
+	g.Reindex()
+	return g, report, nil
+}
+
+// mergeDuplicates merges individuals of cb into matching individuals of ca
+// within g, then families of cb with the same spouses as families of ca.
+func mergeDuplicates(g *Gedcom, ca *Gedcom, cb *Gedcom, opts *MergeOptions, merged func(interface{}, string, *ChangeLog)) {
+	minScore := opts.DuplicateScore
+	if minScore <= 0 {
+		minScore = DefaultAutoMergeScore
+	}
+
+	fromA := make(map[*IndividualRecord]bool)
+	for _, i := range ca.Individual {
+		fromA[i] = true
+	}
+
+	taken := make(map[*IndividualRecord]bool)
+	for _, c := range FindDuplicates(g, minScore) {
+		if fromA[c.A] == fromA[c.B] || taken[c.A] || taken[c.B] {
+			continue
+		}
+		keep, drop := c.A, c.B
+		if fromA[drop] {
+			keep, drop = drop, keep
+		}
+		log, err := g.MergeIndividuals(keep, drop)
+		if err != nil {
+			continue
+		}
+		taken[keep], taken[drop] = true, true
+		merged(drop, keep.Xref, log)
+	}
+
+	for _, drop := range cb.Family {
+		if drop.Husband == nil && drop.Wife == nil {
+			continue
+		}
+		for _, keep := range ca.Family {
+			if keep.Husband != drop.Husband || keep.Wife != drop.Wife {
+				continue
+			}
+			if log, err := g.MergeFamilies(keep, drop); err == nil {
+				merged(drop, keep.Xref, log)
+			}
+			break
+		}
+	}
+}
+
+// duplicatePair identifies a record by its index in the list to keep and one
+// with the same content by its index in the list to drop.
+type duplicatePair struct {
+	keep int
+	drop int
+}
+
+// pairByKey pairs each record to drop with the first record to keep having
+// the same non-empty content key.
+func pairByKey(keep []string, drop []string) []duplicatePair {
+	first := make(map[string]int)
+	for i, k := range keep {
+		if _, seen := first[k]; k != "" && !seen {
+			first[k] = i
+		}
+	}
+
+	var pairs []duplicatePair
+	for i, k := range drop {
+		if j, ok := first[k]; ok {
+			pairs = append(pairs, duplicatePair{keep: j, drop: i})
+		}
+	}
+	return pairs
+}
+
+func repositoryKey(r *RepositoryRecord) string {
+	if r.Address == nil {
+		return contentKey(r.Name)
+	}
+	return contentKey(r.Name, r.Address.Full)
+}
+
+// contentKey joins the parts of a record's content that identify it, ignoring
+// case, punctuation and spacing. It is empty if every part is.
+func contentKey(parts ...string) string {
+	empty := true
+	for i, p := range parts {
+		parts[i] = foldContent(p)
+		if parts[i] != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return ""
+	}
+	return strings.Join(parts, "|")
+}
+
+// foldContent lower-cases the letters of s and keeps its digits, so that
+// sources such as the 1850 and 1860 censuses differ, dropping punctuation
+// and collapsing spaces.
+func foldContent(s string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			return unicode.ToLower(r)
+		case unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, s)), " ")
+}
+
+// renumberPointers returns values with pointers such as @R1@ changed
+// according to renumbered, which maps old xrefs to new.
+func renumberPointers(values []string, renumbered map[string]string) []string {
+	if len(values) == 0 || len(renumbered) == 0 {
+		return values
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v
+		if x := stripXref(v); x != v {
+			if nx, ok := renumbered[x]; ok {
+				out[i] = "@" + nx + "@"
+			}
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
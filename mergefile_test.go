@@ -0,0 +1,115 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestMergeFiles(t *testing.T) {
+	ours, cousin := decodeFile(t, "testdata/ours.ged"), decodeFile(t, "testdata/cousin.ged")
+
+	mg, report, err := Merge(ours, cousin, nil)
+	if err != nil {
+		t.Fatalf("Merge gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Individual count after merge was [%d]", 6, len(mg.Individual)},
+		{"Family count after merge was [%d]", 2, len(mg.Family)},
+		{"Source count after merge was [%d]", 1, len(mg.Source)},
+		{"Repository count after merge was [%d]", 1, len(mg.Repository)},
+		{"Note count after merge was [%d]", 1, len(mg.Note)},
+		{"Submitter count after merge was [%d]", 2, len(mg.Submitter)},
+		{"S1 repository count after merge was [%d]", 1, len(mg.Source[0].Repository)},
+		{"Ours individual count after merge was [%d]", 3, len(ours.Individual)},
+		{"Cousin family count after merge was [%d]", 1, len(cousin.Family)},
+	}.run(t)
+
+	stringTestCases{
+		{"Report", "renumbered F1 as F2\n" +
+			"renumbered I1 as I4\n" +
+			"renumbered I2 as I5\n" +
+			"renumbered I3 as I6\n" +
+			"renumbered N1 as N2\n" +
+			"renumbered R1 as R2\n" +
+			"renumbered S1 as S2\n" +
+			"renumbered U1 as U2\n" +
+			"merged N2 into N1\n" +
+			"merged R2 into R1\n" +
+			"merged S2 into S1", report.String()},
+		{"Header submitter", "Alice Researcher", mg.Header.Submitter.Name},
+		{"Second submitter", "U2", mg.Submitter[1].Xref},
+		{"I4 birth source", "S1", person(t, mg, "I4").Event[0].Citation[0].Source.Xref},
+		{"I4 note", "N1", person(t, mg, "I4").Note[0].Xref},
+		{"F2 children", "I6", xrefs(family(t, mg, "F2").Children())},
+		{"Cousin I1 xref after merge", "I1", cousin.Individual[0].Xref},
+		{"Cousin source after merge", "S1", cousin.Individual[0].Event[0].Citation[0].Source.Xref},
+	}.run(t)
+}
+
+func TestMergeFilesDuplicates(t *testing.T) {
+	ours, cousin := decodeFile(t, "testdata/ours.ged"), decodeFile(t, "testdata/cousin.ged")
+
+	mg, report, err := Merge(ours, cousin, &MergeOptions{MergeDuplicates: true})
+	if err != nil {
+		t.Fatalf("Merge gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Individual count after merge was [%d]", 4, len(mg.Individual)},
+		{"Family count after merge was [%d]", 1, len(mg.Family)},
+		{"I1 birth citation count after merge was [%d]", 2, len(person(t, mg, "I1").Event[0].Citation)},
+		{"I1 note count after merge was [%d]", 1, len(person(t, mg, "I1").Note)},
+	}.run(t)
+
+	stringTestCases{
+		{"Merged I1", "I1", report.Merged["I4"]},
+		{"Merged I2", "I2", report.Merged["I5"]},
+		{"Merged F1", "F1", report.Merged["F2"]},
+		{"Peter", "I6", report.Renumbered["I3"]},
+		{"F1 children", "I3 I6", xrefs(family(t, mg, "F1").Children())},
+		{"Peter's father", "I1", person(t, mg, "I6").Father().Xref},
+	}.run(t)
+}
+
+func TestMergeFilesKeepsNumbers(t *testing.T) {
+	a := &Gedcom{Source: []*SourceRecord{{Xref: "S1", Title: "1850 US Census"}}}
+	b := &Gedcom{Source: []*SourceRecord{{Xref: "S1", Title: "1860 US Census"}, {Xref: "S2", Title: "1850 U.S. census"}}}
+
+	mg, report, err := Merge(a, b, nil)
+	if err != nil {
+		t.Fatalf("Merge gave error %v, expected no error", err)
+	}
+
+	intTestCases{
+		{"Source count after merge was [%d]", 2, len(mg.Source)},
+		{"Merged source count was [%d]", 1, len(report.Merged)},
+	}.run(t)
+
+	stringTestCases{
+		{"Renumbered 1860 census", "S3", report.Renumbered["S1"]},
+		{"Merged 1850 census", "S1", report.Merged["S2"]},
+		{"Kept 1860 census", "1860 US Census", mg.Source[1].Title},
+	}.run(t)
+}
+
+func TestCopy(t *testing.T) {
+	mg := decodeFile(t, "testdata/ours.ged")
+	cg := mg.Copy()
+
+	cg.Individual[0].Name[0].Name = "Bill /Grant/"
+	cg.Family = cg.Family[:0]
+
+	stringTestCases{
+		{"Original name", "William /Grant/", mg.Individual[0].Name[0].Name},
+		{"Copied husband", "Bill /Grant/", cg.Individual[0].Family[0].Family.Husband.Name[0].Name},
+	}.run(t)
+
+	boolTestCases{
+		{"Copied husband is copied individual", true, cg.Individual[0].Family[0].Family.Husband == cg.Individual[0]},
+		{"Copied family is not original", false, cg.Individual[0].Family[0].Family == mg.Family[0]},
+	}.run(t)
+}
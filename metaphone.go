@@ -0,0 +1,467 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+)
+
+// metaphoneLength is the length of Double Metaphone codes.
+const metaphoneLength = 4
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone codes of
+// a name, following Lawrence Philips' algorithm, such as SM0 and XMT for
+// Smith. The alternate code is the same as the primary for names with only
+// one likely pronunciation. 0 stands for the sound of TH and X for SH.
+func DoubleMetaphone(name string) (primary string, alternate string) {
+	v := strings.ToUpper(strings.TrimSpace(name))
+	if v == "" {
+		return "", ""
+	}
+	m := &metaphone{
+		value:         []rune(v),
+		slavoGermanic: strings.ContainsAny(v, "WK") || strings.Contains(v, "CZ"),
+	}
+
+	i := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	if m.at(0) == 'X' {
+		m.add("S")
+		i = 1
+	}
+	for i < len(m.value) && (len(m.primary) < metaphoneLength || len(m.alternate) < metaphoneLength) {
+		i = m.next(i)
+	}
+	return m.code(m.primary), m.code(m.alternate)
+}
+
+// metaphone holds the state of DoubleMetaphone.
+type metaphone struct {
+	value              []rune
+	slavoGermanic      bool
+	primary, alternate []byte
+}
+
+func (m *metaphone) code(b []byte) string {
+	if len(b) > metaphoneLength {
+		b = b[:metaphoneLength]
+	}
+	return string(b)
+}
+
+// at returns the letter at i, or zero if i is out of range.
+func (m *metaphone) at(i int) rune {
+	if i < 0 || i >= len(m.value) {
+		return 0
+	}
+	return m.value[i]
+}
+
+// contains reports whether the n letters from start are any of options.
+func (m *metaphone) contains(start int, n int, options ...string) bool {
+	if start < 0 || start+n > len(m.value) {
+		return false
+	}
+	s := string(m.value[start : start+n])
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) vowel(i int) bool {
+	return strings.ContainsRune("AEIOUY", m.at(i))
+}
+
+// add appends s to both codes.
+func (m *metaphone) add(s string) {
+	m.addBoth(s, s)
+}
+
+func (m *metaphone) addBoth(primary string, alternate string) {
+	m.primary = append(m.primary, primary...)
+	m.alternate = append(m.alternate, alternate...)
+}
+
+// step returns i+2 if the letter after i is any of letters, or i+1.
+func (m *metaphone) step(i int, letters string) int {
+	if m.at(i+1) != 0 && strings.ContainsRune(letters, m.at(i+1)) {
+		return i + 2
+	}
+	return i + 1
+}
+
+// next encodes the letter at i and returns the position of the next letter
+// to encode.
+func (m *metaphone) next(i int) int {
+	last := len(m.value) - 1
+	switch c := m.at(i); c {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		if i == 0 {
+			m.add("A")
+		}
+		return i + 1
+	case 'B':
+		m.add("P")
+		return m.step(i, "B")
+	case 'Ç':
+		m.add("S")
+		return i + 1
+	case 'C':
+		return m.c(i)
+	case 'D':
+		switch {
+		case m.contains(i, 2, "DG") && m.contains(i+2, 1, "I", "E", "Y"):
+			m.add("J")
+			return i + 3
+		case m.contains(i, 2, "DG"):
+			m.add("TK")
+			return i + 2
+		case m.contains(i, 2, "DT", "DD"):
+			m.add("T")
+			return i + 2
+		}
+		m.add("T")
+		return i + 1
+	case 'F':
+		m.add("F")
+		return m.step(i, "F")
+	case 'G':
+		return m.g(i)
+	case 'H':
+		if (i == 0 || m.vowel(i-1)) && m.vowel(i+1) {
+			m.add("H")
+			return i + 2
+		}
+		return i + 1
+	case 'J':
+		return m.j(i)
+	case 'K':
+		m.add("K")
+		return m.step(i, "K")
+	case 'L':
+		if m.at(i+1) != 'L' {
+			m.add("L")
+			return i + 1
+		}
+		if i == last-2 && m.contains(i-1, 4, "ILLO", "ILLA", "ALLE") ||
+			(m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(i-1, 4, "ALLE") {
+			m.addBoth("L", "")
+		} else {
+			m.add("L")
+		}
+		return i + 2
+	case 'M':
+		m.add("M")
+		if m.at(i+1) == 'M' || m.contains(i-1, 3, "UMB") && (i+1 == last || m.contains(i+2, 2, "ER")) {
+			return i + 2
+		}
+		return i + 1
+	case 'N':
+		m.add("N")
+		return m.step(i, "N")
+	case 'Ñ':
+		m.add("N")
+		return i + 1
+	case 'P':
+		if m.at(i+1) == 'H' {
+			m.add("F")
+			return i + 2
+		}
+		m.add("P")
+		return m.step(i, "PB")
+	case 'Q':
+		m.add("K")
+		return m.step(i, "Q")
+	case 'R':
+		if i == last && !m.slavoGermanic && m.contains(i-2, 2, "IE") && !m.contains(i-4, 2, "ME", "MA") {
+			m.addBoth("", "R")
+		} else {
+			m.add("R")
+		}
+		return m.step(i, "R")
+	case 'S':
+		return m.s(i)
+	case 'T':
+		return m.t(i)
+	case 'V':
+		m.add("F")
+		return m.step(i, "V")
+	case 'W':
+		return m.w(i)
+	case 'X':
+		if i == 0 {
+			m.add("S")
+			return i + 1
+		}
+		if !(i == last && (m.contains(i-3, 3, "IAU", "EAU") || m.contains(i-2, 2, "AU", "OU"))) {
+			m.add("KS")
+		}
+		return m.step(i, "CX")
+	case 'Z':
+		if m.at(i+1) == 'H' {
+			m.add("J")
+			return i + 2
+		}
+		if m.contains(i+1, 2, "ZO", "ZI", "ZA") || m.slavoGermanic && i > 0 && m.at(i-1) != 'T' {
+			m.addBoth("S", "TS")
+		} else {
+			m.add("S")
+		}
+		return m.step(i, "Z")
+	}
+	return i + 1
+}
+
+// germanic reports whether the name begins as a German or Dutch one.
+func (m *metaphone) germanic() bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH")
+}
+
+func (m *metaphone) c(i int) int {
+	switch {
+	case m.contains(i, 4, "CHIA") ||
+		i > 1 && !m.vowel(i-2) && m.contains(i-1, 3, "ACH") &&
+			(m.at(i+2) != 'I' && m.at(i+2) != 'E' || m.contains(i-2, 6, "BACHER", "MACHER")):
+		m.add("K")
+		return i + 2
+	case i == 0 && m.contains(i, 6, "CAESAR"):
+		m.add("S")
+		return i + 2
+	case m.contains(i, 2, "CH"):
+		return m.ch(i)
+	case m.contains(i, 2, "CZ") && !m.contains(i-2, 4, "WICZ"):
+		m.addBoth("S", "X")
+		return i + 2
+	case m.contains(i+1, 3, "CIA"):
+		m.add("X")
+		return i + 3
+	case m.contains(i, 2, "CC") && !(i == 1 && m.at(0) == 'M'):
+		if m.contains(i+2, 1, "I", "E", "H") && !m.contains(i+2, 2, "HU") {
+			if i == 1 && m.at(i-1) == 'A' || m.contains(i-1, 5, "UCCEE", "UCCES") {
+				m.add("KS")
+			} else {
+				m.add("X")
+			}
+			return i + 3
+		}
+		m.add("K")
+		return i + 2
+	case m.contains(i, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return i + 2
+	case m.contains(i, 2, "CI", "CE", "CY"):
+		if m.contains(i, 3, "CIO", "CIE", "CIA") {
+			m.addBoth("S", "X")
+		} else {
+			m.add("S")
+		}
+		return i + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.contains(i+1, 2, " C", " Q", " G"):
+		return i + 3
+	case m.contains(i+1, 1, "C", "K", "Q") && !m.contains(i+1, 2, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) ch(i int) int {
+	switch {
+	case i > 0 && m.contains(i, 4, "CHAE"):
+		m.addBoth("K", "X")
+	case i == 0 && (m.contains(i+1, 5, "HARAC", "HARIS") || m.contains(i+1, 3, "HOR", "HYM", "HIA", "HEM")) &&
+		!m.contains(0, 5, "CHORE"):
+		m.add("K")
+	case m.germanic() || m.contains(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") || m.contains(i+2, 1, "T", "S") ||
+		(m.contains(i-1, 1, "A", "O", "U", "E") || i == 0) &&
+			(m.contains(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || i+1 == len(m.value)-1):
+		m.add("K")
+	case i > 0 && m.contains(0, 2, "MC"):
+		m.add("K")
+	case i > 0:
+		m.addBoth("X", "K")
+	default:
+		m.add("X")
+	}
+	return i + 2
+}
+
+func (m *metaphone) g(i int) int {
+	switch {
+	case m.at(i+1) == 'H':
+		return m.gh(i)
+	case m.at(i+1) == 'N':
+		switch {
+		case i == 1 && m.vowel(0) && !m.slavoGermanic:
+			m.addBoth("KN", "N")
+		case !m.contains(i+2, 2, "EY") && m.at(i+1) != 'Y' && !m.slavoGermanic:
+			m.addBoth("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return i + 2
+	case m.contains(i+1, 2, "LI") && !m.slavoGermanic:
+		m.addBoth("KL", "L")
+		return i + 2
+	case i == 0 && (m.at(i+1) == 'Y' || m.contains(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.addBoth("K", "J")
+		return i + 2
+	case (m.contains(i+1, 2, "ER") || m.at(i+1) == 'Y') && !m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(i-1, 1, "E", "I") && !m.contains(i-1, 3, "RGY", "OGY"):
+		m.addBoth("K", "J")
+		return i + 2
+	case m.contains(i+1, 1, "E", "I", "Y") || m.contains(i-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.germanic() || m.contains(i+1, 2, "ET"):
+			m.add("K")
+		case m.contains(i+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.addBoth("J", "K")
+		}
+		return i + 2
+	}
+	m.add("K")
+	return m.step(i, "G")
+}
+
+func (m *metaphone) gh(i int) int {
+	switch {
+	case i > 0 && !m.vowel(i-1):
+		m.add("K")
+	case i == 0:
+		if m.at(i+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case i > 1 && m.contains(i-2, 1, "B", "H", "D") || i > 2 && m.contains(i-3, 1, "B", "H", "D") ||
+		i > 3 && m.contains(i-4, 1, "B", "H"):
+		// silent, as in bough and brought
+	case i > 2 && m.at(i-1) == 'U' && m.contains(i-3, 1, "C", "G", "L", "R", "T"):
+		m.add("F")
+	case m.at(i-1) != 'I':
+		m.add("K")
+	}
+	return i + 2
+}
+
+func (m *metaphone) j(i int) int {
+	if m.contains(i, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		if i == 0 && m.at(i+4) == ' ' || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.addBoth("J", "H")
+		}
+		return i + 1
+	}
+
+	switch {
+	case i == 0:
+		m.addBoth("J", "A")
+	case m.vowel(i-1) && !m.slavoGermanic && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+		m.addBoth("J", "H")
+	case i == len(m.value)-1:
+		m.addBoth("J", "")
+	case !m.contains(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(i-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.step(i, "J")
+}
+
+func (m *metaphone) s(i int) int {
+	last := len(m.value) - 1
+	switch {
+	case m.contains(i-1, 3, "ISL", "YSL"):
+		return i + 1
+	case i == 0 && m.contains(i, 5, "SUGAR"):
+		m.addBoth("X", "S")
+		return i + 1
+	case m.contains(i, 2, "SH"):
+		if m.contains(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return i + 2
+	case m.contains(i, 3, "SIO", "SIA") || m.contains(i, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addBoth("S", "X")
+		}
+		return i + 3
+	case i == 0 && m.contains(i+1, 1, "M", "N", "L", "W") || m.contains(i+1, 1, "Z"):
+		m.addBoth("S", "X")
+		return m.step(i, "Z")
+	case m.contains(i, 2, "SC"):
+		switch {
+		case m.at(i+2) == 'H' && m.contains(i+3, 2, "ER", "EN"):
+			m.addBoth("X", "SK")
+		case m.at(i+2) == 'H' && m.contains(i+3, 2, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case m.at(i+2) == 'H' && i == 0 && !m.vowel(3) && m.at(3) != 'W':
+			m.addBoth("X", "S")
+		case m.at(i+2) == 'H':
+			m.add("X")
+		case m.contains(i+2, 1, "I", "E", "Y"):
+			m.add("S")
+		default:
+			m.add("SK")
+		}
+		return i + 3
+	}
+
+	if i == last && m.contains(i-2, 2, "AI", "OI") {
+		m.addBoth("", "S")
+	} else {
+		m.add("S")
+	}
+	return m.step(i, "SZ")
+}
+
+func (m *metaphone) t(i int) int {
+	switch {
+	case m.contains(i, 4, "TION") || m.contains(i, 3, "TIA", "TCH"):
+		m.add("X")
+		return i + 3
+	case m.contains(i, 2, "TH") || m.contains(i, 3, "TTH"):
+		if m.contains(i+2, 2, "OM", "AM") || m.germanic() {
+			m.add("T")
+		} else {
+			m.addBoth("0", "T")
+		}
+		return i + 2
+	}
+	m.add("T")
+	return m.step(i, "TD")
+}
+
+func (m *metaphone) w(i int) int {
+	switch {
+	case m.contains(i, 2, "WR"):
+		m.add("R")
+		return i + 2
+	case i == 0 && m.vowel(i+1):
+		m.addBoth("A", "F")
+	case i == 0 && m.contains(i, 2, "WH"):
+		m.add("A")
+	case i == len(m.value)-1 && m.vowel(i-1) || m.contains(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		m.addBoth("", "F")
+	case m.contains(i, 4, "WICZ", "WITZ"):
+		m.addBoth("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
@@ -0,0 +1,35 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+)
+
+// Given returns the given names of a GEDCOM personal name, the part before
+// the surname, which is marked off by slashes as in "John /Smith/ Jr". A name
+// without slashes is taken to be wholly given names.
+func (n *NameRecord) Given() string {
+	i := strings.Index(n.Name, "/")
+	if i < 0 {
+		return strings.Join(strings.Fields(n.Name), " ")
+	}
+	return strings.Join(strings.Fields(n.Name[:i]), " ")
+}
+
+// Surname returns the surname of a GEDCOM personal name, the part between
+// slashes, or an empty string if there is none.
+func (n *NameRecord) Surname() string {
+	i := strings.Index(n.Name, "/")
+	if i < 0 {
+		return ""
+	}
+	rest := n.Name[i+1:]
+	if j := strings.Index(rest, "/"); j >= 0 {
+		rest = rest[:j]
+	}
+	return strings.Join(strings.Fields(rest), " ")
+}
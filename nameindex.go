@@ -0,0 +1,191 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// NameMatch is how NameIndex.Search compares surnames.
+//
+// Beider–Morse phonetic matching is not supported. It first guesses the
+// language of a name and then applies that language's rules, and its rule
+// tables run to thousands of entries that would have to be kept in step
+// with the reference implementation. Daitch–Mokotoff covers much of the same
+// ground for Slavic, Germanic and Yiddish surnames.
+type NameMatch int
+
+// The ways NameIndex.Search can compare surnames.
+const (
+	MatchExact          NameMatch = iota // the same surname, ignoring case and punctuation
+	MatchPrefix                          // surnames beginning with the one sought
+	MatchSoundex                         // the same American Soundex code
+	MatchDaitchMokotoff                  // any Daitch–Mokotoff code in common
+	MatchMetaphone                       // any Double Metaphone code in common
+)
+
+// NameQuery is a search of a NameIndex.
+type NameQuery struct {
+	Surname string // the surname sought; empty to match every name
+	Match   NameMatch
+
+	BornFrom int    // the earliest year of birth, or zero for any
+	BornTo   int    // the latest year of birth, or zero for any
+	Place    string // part of the place of any event of the individual, or empty for any
+}
+
+// NameEntry is a name of an individual in a NameIndex.
+type NameEntry struct {
+	Individual *IndividualRecord
+	Name       *NameRecord
+}
+
+// NameIndex is an index of the names of the individuals in a Gedcom,
+// including alternate names, by surname and phonetic code. It is not updated
+// when the Gedcom changes, so a new index must be made after changes.
+type NameIndex struct {
+	entries  []*NameEntry // sorted by surname and given names
+	surnames []string     // the folded surname of each entry
+	codes    map[NameMatch]map[string][]int
+}
+
+// NewNameIndex returns an index of the names of the individuals in g.
+func NewNameIndex(g *Gedcom) *NameIndex {
+	type sortKey struct{ surname, given string }
+	var entries []*NameEntry
+	keys := make(map[*NameEntry]sortKey)
+	for _, i := range g.Individual {
+		for _, n := range i.Name {
+			e := &NameEntry{Individual: i, Name: n}
+			keys[e] = sortKey{foldName(n.Surname()), foldName(n.Given())}
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(j, k int) bool {
+		a, b := keys[entries[j]], keys[entries[k]]
+		if a.surname != b.surname {
+			return a.surname < b.surname
+		}
+		return a.given < b.given
+	})
+
+	x := &NameIndex{
+		entries:  entries,
+		surnames: make([]string, len(entries)),
+		codes:    make(map[NameMatch]map[string][]int),
+	}
+	for _, m := range []NameMatch{MatchExact, MatchSoundex, MatchDaitchMokotoff, MatchMetaphone} {
+		x.codes[m] = make(map[string][]int)
+	}
+	for n, e := range entries {
+		x.surnames[n] = keys[e].surname
+		for m, codes := range x.codes {
+			for _, c := range surnameCodes(m, e.Name.Surname()) {
+				codes[c] = append(codes[c], n)
+			}
+		}
+	}
+	return x
+}
+
+// surnameCodes returns the keys under which a surname is indexed for m.
+func surnameCodes(m NameMatch, surname string) []string {
+	switch m {
+	case MatchExact:
+		return []string{foldName(surname)}
+	case MatchSoundex:
+		if c := Soundex(surname); c != "" {
+			return []string{c}
+		}
+	case MatchDaitchMokotoff:
+		return DaitchMokotoff(surname)
+	case MatchMetaphone:
+		primary, alternate := DoubleMetaphone(foldName(surname))
+		if primary == alternate {
+			return []string{primary}
+		}
+		return []string{primary, alternate}
+	}
+	return nil
+}
+
+// Search returns the names matching q, one for each individual, sorted by
+// surname and given names. Individuals must have a birth, christening or
+// baptism that may be within the years given by q, and an event whose place
+// contains q.Place, ignoring case and punctuation.
+func (x *NameIndex) Search(q NameQuery) []*NameEntry {
+	var found []int
+	switch surname := foldName(q.Surname); {
+	case surname == "":
+		for n := range x.entries {
+			found = append(found, n)
+		}
+	case q.Match == MatchPrefix:
+		for n := sort.SearchStrings(x.surnames, surname); n < len(x.surnames) && strings.HasPrefix(x.surnames[n], surname); n++ {
+			found = append(found, n)
+		}
+	default:
+		codes, ok := x.codes[q.Match]
+		if !ok {
+			return nil
+		}
+		for _, c := range surnameCodes(q.Match, q.Surname) {
+			found = append(found, codes[c]...)
+		}
+		sort.Ints(found)
+	}
+
+	var results []*NameEntry
+	seen := make(map[*IndividualRecord]bool)
+	for _, n := range found {
+		e := x.entries[n]
+		if seen[e.Individual] || !bornWithin(e.Individual, q.BornFrom, q.BornTo) || !livedAt(e.Individual, q.Place) {
+			continue
+		}
+		seen[e.Individual] = true
+		results = append(results, e)
+	}
+	return results
+}
+
+// bornWithin reports whether ind may have been born between the years from
+// and to, either of which may be zero for no limit.
+func bornWithin(ind *IndividualRecord, from int, to int) bool {
+	if from == 0 && to == 0 {
+		return true
+	}
+	r, ok := bornRange(ind)
+	if !ok {
+		return false
+	}
+	var years DateRange
+	if from != 0 {
+		years.Earliest = time.Date(from, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if to != 0 {
+		years.Latest = time.Date(to, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return r.Overlaps(years)
+}
+
+// livedAt reports whether ind has an event or attribute with a place
+// containing place, or whether place is empty.
+func livedAt(ind *IndividualRecord, place string) bool {
+	place = foldName(place)
+	if place == "" {
+		return true
+	}
+	for _, events := range [][]*EventRecord{ind.Event, ind.Attribute} {
+		for _, e := range events {
+			if strings.Contains(foldName(e.Place.Name), place) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func nameXrefs(entries []*NameEntry) string {
+	var x []string
+	for _, e := range entries {
+		x = append(x, e.Individual.Xref)
+	}
+	return strings.Join(x, " ")
+}
+
+func TestNameIndexSearch(t *testing.T) {
+	x := NewNameIndex(decodeFile(t, "testdata/names.ged"))
+	search := func(q NameQuery) string {
+		return nameXrefs(x.Search(q))
+	}
+
+	stringTestCases{
+		{"Exact", "I1", search(NameQuery{Surname: "SMITH"})},
+		{"Exact alternate name", "I2 I7", search(NameQuery{Surname: "Kelly"})},
+		{"Prefix", "I1 I6", search(NameQuery{Surname: "Smi", Match: MatchPrefix})},
+		{"Soundex", "I3 I1 I2", search(NameQuery{Surname: "Smith", Match: MatchSoundex})},
+		{"Daitch-Mokotoff", "I3 I1 I2", search(NameQuery{Surname: "Smith", Match: MatchDaitchMokotoff})},
+		{"Daitch-Mokotoff spelling variant", "I5 I4", search(NameQuery{Surname: "Moskowitz", Match: MatchDaitchMokotoff})},
+		{"Metaphone", "I3 I1 I2", search(NameQuery{Surname: "Smith", Match: MatchMetaphone})},
+		{"Unknown surname", "", search(NameQuery{Surname: "Jones", Match: MatchSoundex})},
+		{"Born within years", "I2", search(NameQuery{Surname: "Kelly", BornFrom: 1850})},
+		{"Unknown birth excluded", "I4", search(NameQuery{Surname: "Moskowitz", Match: MatchDaitchMokotoff, BornTo: 1900})},
+		{"Place", "I5", search(NameQuery{Surname: "Moskowitz", Match: MatchDaitchMokotoff, Place: "new york"})},
+		{"Every name in a place", "I2 I7 I1", search(NameQuery{Place: "Ireland"})},
+		{"Born within years in a place", "I7", search(NameQuery{Place: "Cork", BornTo: 1830})},
+		{"First matching name", "Mary /Kelly/", x.Search(NameQuery{Place: "Dublin"})[0].Name.Name},
+	}.run(t)
+}
+
+func TestDaitchMokotoff(t *testing.T) {
+	stringTestCases{
+		{"Daitch-Mokotoff of Moskowitz", "645740", strings.Join(DaitchMokotoff("Moskowitz"), " ")},
+		{"Daitch-Mokotoff of Peters", "734000 739400", strings.Join(DaitchMokotoff("Peters"), " ")},
+		{"Daitch-Mokotoff of Auerbach", "097400 097500", strings.Join(DaitchMokotoff("Auerbach"), " ")},
+		{"Daitch-Mokotoff of Jackson", "145460 154600 445460 454600", strings.Join(DaitchMokotoff("Jackson"), " ")},
+		{"Daitch-Mokotoff of Schmidt", "463000", strings.Join(DaitchMokotoff("Schmidt"), " ")},
+		{"Daitch-Mokotoff of empty name", "", strings.Join(DaitchMokotoff(""), " ")},
+	}.run(t)
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	metaphone := func(name string) string {
+		primary, alternate := DoubleMetaphone(name)
+		return primary + " " + alternate
+	}
+
+	stringTestCases{
+		{"Double Metaphone of Smith", "SM0 XMT", metaphone("Smith")},
+		{"Double Metaphone of Schmidt", "XMT SMT", metaphone("Schmidt")},
+		{"Double Metaphone of Catherine", "K0RN KTRN", metaphone("Catherine")},
+		{"Double Metaphone of Kathryn", "K0RN KTRN", metaphone("Kathryn")},
+		{"Double Metaphone of Xavier", "SF SFR", metaphone("Xavier")},
+		{"Double Metaphone of Jose", "HS HS", metaphone("Jose")},
+		{"Double Metaphone of empty name", " ", metaphone("")},
+	}.run(t)
+}
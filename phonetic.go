@@ -0,0 +1,280 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// soundexCodes gives the Soundex digit of each letter, with 0 for the letters
+// that are dropped.
+var soundexCodes = map[rune]byte{
+	'A': '0', 'E': '0', 'I': '0', 'O': '0', 'U': '0', 'Y': '0', 'H': '0', 'W': '0',
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// Soundex returns the American Soundex code of a name, such as R163 for
+// Robert and Rupert, or an empty string if the name has no letters A to Z.
+func Soundex(name string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for _, r := range strings.ToUpper(name) {
+		digit, ok := soundexCodes[r]
+		if !ok {
+			continue
+		}
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = digit
+			continue
+		}
+		switch {
+		case r == 'H' || r == 'W':
+			// H and W do not separate letters with the same code
+		case digit == '0':
+			last = 0
+		case digit != last:
+			code = append(code, digit)
+			last = digit
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions needed to turn a into b.
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// similarity returns how alike two names are, from 0 for nothing in common
+// to 1 for names that differ only in case and punctuation.
+func similarity(a string, b string) float64 {
+	a, b = foldName(a), foldName(b)
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// foldName lower cases a name and drops everything but letters and spaces.
+func foldName(s string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			return unicode.ToLower(r)
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, s)), " ")
+}
+
+// dmCodes are the Daitch–Mokotoff codes of a letter or group of letters at
+// the start of a name, before a vowel and elsewhere. Alternatives are
+// separated by |, and an empty code means the letters are not coded.
+type dmCodes struct {
+	start, vowel, other string
+}
+
+// dmRules are the Daitch–Mokotoff codes by letter or group of letters.
+var dmRules = map[string]dmCodes{
+	"AI": {"0", "1", ""}, "AJ": {"0", "1", ""}, "AY": {"0", "1", ""},
+	"AU":  {"0", "7", ""},
+	"A":   {"0", "", ""},
+	"B":   {"7", "7", "7"},
+	"CHS": {"5", "54", "54"},
+	"CH":  {"5|4", "5|4", "5|4"},
+	"CK":  {"5|45", "5|45", "5|45"},
+	"CZ":  {"4", "4", "4"}, "CS": {"4", "4", "4"}, "CSZ": {"4", "4", "4"}, "CZS": {"4", "4", "4"},
+	"C":   {"5|4", "5|4", "5|4"},
+	"DRZ": {"4", "4", "4"}, "DRS": {"4", "4", "4"},
+	"DS": {"4", "4", "4"}, "DSH": {"4", "4", "4"}, "DSZ": {"4", "4", "4"},
+	"DZ": {"4", "4", "4"}, "DZH": {"4", "4", "4"}, "DZS": {"4", "4", "4"},
+	"D": {"3", "3", "3"}, "DT": {"3", "3", "3"},
+	"EI": {"0", "1", ""}, "EJ": {"0", "1", ""}, "EY": {"0", "1", ""},
+	"EU": {"1", "1", ""},
+	"E":  {"0", "", ""},
+	"FB": {"7", "7", "7"},
+	"F":  {"7", "7", "7"},
+	"G":  {"5", "5", "5"},
+	"H":  {"5", "5", ""},
+	"IA": {"1", "", ""}, "IE": {"1", "", ""}, "IO": {"1", "", ""}, "IU": {"1", "", ""},
+	"I":  {"0", "", ""},
+	"J":  {"1|4", "|4", "|4"},
+	"KS": {"5", "54", "54"},
+	"KH": {"5", "5", "5"},
+	"K":  {"5", "5", "5"},
+	"L":  {"8", "8", "8"},
+	"M":  {"6", "6", "6"},
+	"N":  {"6", "6", "6"},
+	"OI": {"0", "1", ""}, "OJ": {"0", "1", ""}, "OY": {"0", "1", ""},
+	"O": {"0", "", ""},
+	"P": {"7", "7", "7"}, "PF": {"7", "7", "7"}, "PH": {"7", "7", "7"},
+	"Q":  {"5", "5", "5"},
+	"RZ": {"94|4", "94|4", "94|4"}, "RS": {"94|4", "94|4", "94|4"},
+	"R":       {"9", "9", "9"},
+	"SCHTSCH": {"2", "4", "4"}, "SCHTSH": {"2", "4", "4"}, "SCHTCH": {"2", "4", "4"},
+	"SCH":   {"4", "4", "4"},
+	"SHTCH": {"2", "4", "4"}, "SHCH": {"2", "4", "4"}, "SHTSH": {"2", "4", "4"},
+	"SHT": {"2", "43", "43"}, "SCHT": {"2", "43", "43"}, "SCHD": {"2", "43", "43"},
+	"SH":   {"4", "4", "4"},
+	"STCH": {"2", "4", "4"}, "STSCH": {"2", "4", "4"}, "SC": {"2", "4", "4"},
+	"STRZ": {"2", "4", "4"}, "STRS": {"2", "4", "4"}, "STSH": {"2", "4", "4"},
+	"ST":   {"2", "43", "43"},
+	"SZCZ": {"2", "4", "4"}, "SZCS": {"2", "4", "4"},
+	"SZT": {"2", "43", "43"}, "SHD": {"2", "43", "43"}, "SZD": {"2", "43", "43"}, "SD": {"2", "43", "43"},
+	"SZ":  {"4", "4", "4"},
+	"S":   {"4", "4", "4"},
+	"TCH": {"4", "4", "4"}, "TTCH": {"4", "4", "4"}, "TTSCH": {"4", "4", "4"},
+	"TH":  {"3", "3", "3"},
+	"TRZ": {"4", "4", "4"}, "TRS": {"4", "4", "4"},
+	"TSCH": {"4", "4", "4"}, "TSH": {"4", "4", "4"},
+	"TS": {"4", "4", "4"}, "TTS": {"4", "4", "4"}, "TTSZ": {"4", "4", "4"}, "TC": {"4", "4", "4"},
+	"TZ": {"4", "4", "4"}, "TTZ": {"4", "4", "4"}, "TZS": {"4", "4", "4"}, "TSZ": {"4", "4", "4"},
+	"T":  {"3", "3", "3"},
+	"UI": {"0", "1", ""}, "UJ": {"0", "1", ""}, "UY": {"0", "1", ""},
+	"UE":  {"0", "", ""},
+	"U":   {"0", "", ""},
+	"V":   {"7", "7", "7"},
+	"W":   {"7", "7", "7"},
+	"X":   {"5", "54", "54"},
+	"Y":   {"1", "", ""},
+	"ZDZ": {"2", "4", "4"}, "ZDZH": {"2", "4", "4"}, "ZHDZH": {"2", "4", "4"},
+	"ZD": {"2", "43", "43"}, "ZHD": {"2", "43", "43"},
+	"ZH": {"4", "4", "4"}, "ZS": {"4", "4", "4"}, "ZSCH": {"4", "4", "4"}, "ZSH": {"4", "4", "4"},
+	"Z": {"4", "4", "4"},
+}
+
+// dmLongest is the length of the longest group of letters in dmRules.
+const dmLongest = 7
+
+// dmLength is the length of Daitch–Mokotoff codes.
+const dmLength = 6
+
+// DaitchMokotoff returns the Daitch–Mokotoff Soundex codes of a name, such as
+// 734000 and 739400 for Peters, in order. Names with letters that may be
+// pronounced more than one way have more than one code. Letters other than A
+// to Z are ignored, and a name without any has no codes.
+func DaitchMokotoff(name string) []string {
+	var letters []byte
+	for _, r := range strings.ToUpper(name) {
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, byte(r))
+		}
+	}
+	if len(letters) == 0 {
+		return nil
+	}
+
+	type branch struct {
+		code string
+		last string // the code given to the previous letters
+	}
+	branches := []branch{{}}
+	for i := 0; i < len(letters); {
+		n := dmLongest
+		if n > len(letters)-i {
+			n = len(letters) - i
+		}
+		for ; n > 1; n-- {
+			if _, ok := dmRules[string(letters[i:i+n])]; ok {
+				break
+			}
+		}
+		rule := dmRules[string(letters[i:i+n])]
+		codes := rule.other
+		switch {
+		case i == 0:
+			codes = rule.start
+		case i+n < len(letters) && strings.IndexByte("AEIOU", letters[i+n]) >= 0:
+			codes = rule.vowel
+		}
+
+		// MN and NM are coded twice although the letters have the same code
+		force := i > 0 && (letters[i-1] == 'M' && letters[i] == 'N' || letters[i-1] == 'N' && letters[i] == 'M')
+
+		seen := make(map[branch]bool)
+		var next []branch
+		for _, b := range branches {
+			for _, c := range strings.Split(codes, "|") {
+				nb := b
+				if i == 0 || force || !strings.HasSuffix(b.last, c) {
+					nb.code += c
+					if len(nb.code) > dmLength {
+						nb.code = nb.code[:dmLength]
+					}
+				}
+				nb.last = c
+				if !seen[nb] {
+					seen[nb] = true
+					next = append(next, nb)
+				}
+			}
+		}
+		branches = next
+		i += n
+	}
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, b := range branches {
+		c := b.code + strings.Repeat("0", dmLength-len(b.code))
+		if !seen[c] {
+			seen[c] = true
+			codes = append(codes, c)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
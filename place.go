@@ -0,0 +1,207 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+)
+
+// Place is a distinct location shared by all the events that occurred there.
+// Its name is split into jurisdictions, smallest first, which are labelled
+// according to the place's FORM or, failing that, the header's PLAC FORM.
+type Place struct {
+	Name          string
+	Form          []string
+	Jurisdictions []string
+	Phonetic      []*PlaceVariantRecord
+	Romanized     []*PlaceVariantRecord
+	Latitude      string
+	Longitude     string
+	Event         []*EventRecord
+}
+
+// defaultPlaceForm is assumed for places when no FORM is given.
+var defaultPlaceForm = []string{"City", "County", "State", "Country"}
+
+// placeLevels maps the jurisdiction names used in PLAC FORM to the levels
+// offered by Place.
+var placeLevels = map[string]string{
+	"city":         "city",
+	"town":         "city",
+	"village":      "city",
+	"hamlet":       "city",
+	"township":     "city",
+	"municipality": "city",
+	"parish":       "city",
+	"county":       "county",
+	"shire":        "county",
+	"district":     "county",
+	"state":        "state",
+	"province":     "state",
+	"region":       "state",
+	"country":      "country",
+	"nation":       "country",
+}
+
+// Jurisdiction returns the part of the place name labelled with level in the
+// place's form, for example "County". Common synonyms such as "Town" for
+// "City" or "Province" for "State" are accepted. It returns an empty string if
+// the place has no such jurisdiction.
+func (p *Place) Jurisdiction(level string) string {
+	want := strings.ToLower(level)
+	if l, ok := placeLevels[want]; ok {
+		want = l
+	}
+
+	for i, f := range p.alignedForm() {
+		f = strings.ToLower(f)
+		if l, ok := placeLevels[f]; ok {
+			f = l
+		}
+		if f == want {
+			return p.Jurisdictions[i]
+		}
+	}
+	return ""
+}
+
+// City returns the city, town or village of the place.
+func (p *Place) City() string {
+	return p.Jurisdiction("city")
+}
+
+// County returns the county of the place.
+func (p *Place) County() string {
+	return p.Jurisdiction("county")
+}
+
+// State returns the state or province of the place.
+func (p *Place) State() string {
+	return p.Jurisdiction("state")
+}
+
+// Country returns the country of the place.
+func (p *Place) Country() string {
+	return p.Jurisdiction("country")
+}
+
+// alignedForm returns one form label per jurisdiction. When a name has fewer
+// parts than its form the parts are aligned with the end of the form, since
+// the largest jurisdictions are the ones least often omitted.
+func (p *Place) alignedForm() []string {
+	form := p.Form
+	if len(form) == 0 {
+		form = defaultPlaceForm
+	}
+
+	labels := make([]string, len(p.Jurisdictions))
+	offset := len(form) - len(p.Jurisdictions)
+	if offset < 0 {
+		offset = 0
+	}
+	for i := range labels {
+		if i+offset < len(form) {
+			labels[i] = form[i+offset]
+		}
+	}
+	return labels
+}
+
+// splitPlace splits a comma separated place name or form into its trimmed parts.
+func splitPlace(name string) []string {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+
+	parts := strings.Split(name, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// indexPlaces builds the shared place index for g and links every event's
+// PlaceRecord to its entry.
+func indexPlaces(g *Gedcom) []*Place {
+	places := make([]*Place, 0)
+	byKey := make(map[string]*Place)
+
+	var headerForm []string
+	if g.Header != nil {
+		headerForm = splitPlace(g.Header.PlaceForm)
+	}
+
+	add := func(events []*EventRecord) {
+		for _, e := range events {
+			pr := &e.Place
+			parts := splitPlace(pr.Name)
+			if len(parts) == 0 {
+				continue
+			}
+
+			form := headerForm
+			if pr.Form != "" {
+				form = splitPlace(pr.Form)
+			}
+
+			key := strings.ToLower(strings.Join(parts, ", "))
+			p, found := byKey[key]
+			if !found {
+				p = &Place{
+					Name:          strings.Join(parts, ", "),
+					Form:          form,
+					Jurisdictions: parts,
+				}
+				byKey[key] = p
+				places = append(places, p)
+			}
+
+			if pr.Form != "" && len(p.Form) == 0 {
+				p.Form = form
+			}
+			if p.Latitude == "" && p.Longitude == "" {
+				p.Latitude = pr.Latitude
+				p.Longitude = pr.Longitude
+			}
+			p.Phonetic = appendPlaceVariants(p.Phonetic, pr.Phonetic)
+			p.Romanized = appendPlaceVariants(p.Romanized, pr.Romanized)
+			p.Event = append(p.Event, e)
+			pr.Place = p
+		}
+	}
+
+	for _, i := range g.Individual {
+		add(i.Event)
+		add(i.Attribute)
+	}
+	for _, f := range g.Family {
+		add(f.Event)
+	}
+	for _, s := range g.Source {
+		if s.EventData != nil {
+			add(s.EventData.Event)
+		}
+	}
+
+	return places
+}
+
+// appendPlaceVariants adds the variants in vs to list, skipping duplicates.
+func appendPlaceVariants(list []*PlaceVariantRecord, vs []*PlaceVariantRecord) []*PlaceVariantRecord {
+	for _, v := range vs {
+		dup := false
+		for _, l := range list {
+			if l.Name == v.Name && l.Type == v.Type {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,48 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestPlaceIndex(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/places.ged")
+
+	salem := pg.Individual[0].Event[0].Place.Place
+	essex := pg.Individual[0].Event[1].Place.Place
+	tokyo := pg.Individual[1].Event[0].Place.Place
+
+	intTestCases{
+		{"Place index length was [%d]", 3, len(pg.Place)},
+		{"Salem event count was [%d]", 3, len(salem.Event)},
+		{"Tokyo phonetic variant count was [%d]", 1, len(tokyo.Phonetic)},
+	}.run(t)
+
+	boolTestCases{
+		{"Residence shares birth place", true, pg.Individual[0].Attribute[0].Place.Place == salem},
+		{"Marriage shares birth place", true, pg.Family[0].Event[0].Place.Place == salem},
+	}.run(t)
+
+	stringTestCases{
+		{"Header place form", "City, County, State, Country", pg.Header.PlaceForm},
+		{"Salem name", "Salem, Essex, Massachusetts, USA", salem.Name},
+		{"Salem city", "Salem", salem.City()},
+		{"Salem county", "Essex", salem.County()},
+		{"Salem state", "Massachusetts", salem.State()},
+		{"Salem country", "USA", salem.Country()},
+		{"Salem latitude", "N42.5195", salem.Latitude},
+		{"Essex city", "", essex.City()},
+		{"Essex county", "Essex", essex.County()},
+		{"Essex country", "USA", essex.Country()},
+		{"Tokyo prefecture", "東京", tokyo.Jurisdiction("Prefecture")},
+		{"Tokyo country", "日本", tokyo.Country()},
+		{"Tokyo phonetic", "Toukyou, Nihon", tokyo.Phonetic[0].Name},
+		{"Tokyo phonetic type", "kana", tokyo.Phonetic[0].Type},
+		{"Tokyo romanized", "Tokyo, Japan", tokyo.Romanized[0].Name},
+		{"Tokyo romanized type", "romaji", tokyo.Romanized[0].Type},
+	}.run(t)
+}
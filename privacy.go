@@ -0,0 +1,219 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PrivacyAction is what Privatize does with private people.
+type PrivacyAction int
+
+// The actions Privatize can take.
+const (
+	// RedactPrivate names private people "Living" and strips their events,
+	// attributes, notes, objects and citations, keeping their sex and family
+	// links. The events, notes, objects and citations of their families are
+	// stripped too.
+	RedactPrivate PrivacyAction = iota
+
+	// RemovePrivate removes private people altogether, with families left
+	// without any members.
+	RemovePrivate
+)
+
+// PrivacyPolicy controls who Privatize treats as private and what it does
+// with them.
+type PrivacyPolicy struct {
+	Action PrivacyAction
+
+	MaxLifespan  int       // people born within this many years are living unless known to have died
+	MinParentAge int       // the youngest anyone has a child, for estimating births from children
+	MaxParentAge int       // the oldest anyone has a child, for estimating births from parents
+	Now          time.Time // the date from which ages are reckoned; zero means today
+}
+
+// DefaultPrivacyPolicy returns a policy that redacts private people, with
+// conventional ages.
+func DefaultPrivacyPolicy() PrivacyPolicy {
+	return PrivacyPolicy{
+		Action:       RedactPrivate,
+		MaxLifespan:  110,
+		MinParentAge: 12,
+		MaxParentAge: 60,
+	}
+}
+
+// Privatize returns a copy of g for publication in which people who are
+// probably living, or whose RESN restricts them as confidential or private,
+// are redacted or removed according to p. Families restricted by RESN, like
+// those of private people, keep only their members. Events restricted by RESN
+// are removed for everyone. Sources, notes, objects, repositories and submitters
+// that only private people referred to are left out. g is unchanged.
+func Privatize(g *Gedcom, p PrivacyPolicy) *Gedcom {
+	c := g.Copy()
+
+	private := make(map[*IndividualRecord]bool)
+	for _, i := range c.Individual {
+		if isRestricted(i.Restriction) || ProbablyLiving(i, p) {
+			private[i] = true
+		}
+	}
+
+	keep := make(map[interface{}]bool)
+	var individuals []*IndividualRecord
+	for _, i := range c.Individual {
+		i.Event = unrestrictedEvents(i.Event)
+		i.Attribute = unrestrictedEvents(i.Attribute)
+		if private[i] {
+			if p.Action == RemovePrivate {
+				continue
+			}
+			redactIndividual(i)
+		}
+		keep[i] = true
+		individuals = append(individuals, i)
+	}
+
+	seen := make(map[interface{}]bool)
+	var families []*FamilyRecord
+	for _, f := range c.Family {
+		f.Event = unrestrictedEvents(f.Event)
+		if isRestricted(f.Restriction) || private[f.Husband] || private[f.Wife] {
+			f.Event, f.NumberOfChildren = nil, nil
+			f.Note, f.Object, f.Citation = nil, nil, nil
+		}
+		pruneLinks(reflect.ValueOf(f).Elem(), keep, seen)
+		if f.Husband != nil || f.Wife != nil || len(f.Child) > 0 {
+			families = append(families, f)
+		}
+	}
+	for _, f := range families {
+		keep[f] = true
+	}
+	for _, i := range individuals {
+		pruneLinks(reflect.ValueOf(i).Elem(), keep, seen)
+	}
+
+	return subset(c, individuals, families)
+}
+
+// ProbablyLiving reports whether ind may still be alive according to the
+// ages in p. Anyone with a death, burial or cremation is dead, as is anyone
+// born, or with an event of their own or of their families, more than
+// p.MaxLifespan years ago. So is anyone with a parent born long enough ago,
+// or a child born long enough ago, that they must have been born before
+// then. Everyone else, including those with no dates at all, is taken to be
+// living.
+func ProbablyLiving(ind *IndividualRecord, p PrivacyPolicy) bool {
+	now := p.Now
+	if now.IsZero() {
+		now = timeNow()
+	}
+	cutoff := now.AddDate(-p.MaxLifespan, 0, 0)
+	before := func(r DateRange, years int) bool {
+		return !r.Latest.IsZero() && r.Latest.AddDate(years, 0, 0).Before(cutoff)
+	}
+
+	for _, e := range ind.Event {
+		switch e.Tag {
+		case "DEAT", "BURI", "CREM":
+			return false
+		}
+	}
+
+	if r, ok := bornRange(ind); ok {
+		if before(r, 0) {
+			return false
+		}
+		if !r.Earliest.IsZero() && !r.Earliest.Before(cutoff) {
+			return true
+		}
+	}
+
+	events := append(append([]*EventRecord(nil), ind.Event...), ind.Attribute...)
+	for _, l := range ind.Family {
+		if l.Family != nil {
+			events = append(events, l.Family.Event...)
+		}
+	}
+	for _, e := range events {
+		if r, ok := dateRange(e); ok && before(r, 0) {
+			return false
+		}
+	}
+
+	for _, f := range ind.ParentFamilies() {
+		for _, parent := range []*IndividualRecord{f.Husband, f.Wife} {
+			if parent == nil {
+				continue
+			}
+			if r, ok := bornRange(parent); ok && before(r, p.MaxParentAge) {
+				return false
+			}
+		}
+	}
+	for _, child := range ind.Children() {
+		if r, ok := bornRange(child); ok && before(r, -p.MinParentAge) {
+			return false
+		}
+	}
+	return true
+}
+
+// bornRange returns the range of ind's birth date, or failing that of their
+// christening or baptism.
+func bornRange(ind *IndividualRecord) (DateRange, bool) {
+	for _, tag := range []string{"BIRT", "CHR", "BAPM"} {
+		if r, ok := ind.eventRange(tag); ok {
+			return r, true
+		}
+	}
+	return DateRange{}, false
+}
+
+// isRestricted reports whether a RESN value marks a record as confidential or
+// private. GEDCOM 7.0 allows a list of restrictions such as
+// "CONFIDENTIAL, LOCKED".
+func isRestricted(resn string) bool {
+	for _, r := range strings.Split(resn, ",") {
+		switch strings.ToLower(strings.TrimSpace(r)) {
+		case "confidential", "privacy":
+			return true
+		}
+	}
+	return false
+}
+
+// unrestrictedEvents returns events without those restricted by RESN.
+func unrestrictedEvents(events []*EventRecord) []*EventRecord {
+	var kept []*EventRecord
+	for _, e := range events {
+		if !isRestricted(e.Restriction) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// redactIndividual names ind "Living" and strips everything but their sex
+// and family links.
+func redactIndividual(ind *IndividualRecord) {
+	*ind = IndividualRecord{
+		Xref:    ind.Xref,
+		Sex:     ind.Sex,
+		Name:    []*NameRecord{{Name: "Living"}},
+		Parents: ind.Parents,
+		Family:  ind.Family,
+	}
+	for _, links := range [][]*FamilyLinkRecord{ind.Parents, ind.Family} {
+		for _, l := range links {
+			l.Note = nil
+		}
+	}
+}
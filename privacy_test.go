@@ -0,0 +1,78 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+	"time"
+)
+
+func privacyPolicy(action PrivacyAction) PrivacyPolicy {
+	p := DefaultPrivacyPolicy()
+	p.Action = action
+	p.Now = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return p
+}
+
+func TestProbablyLiving(t *testing.T) {
+	g := decodeFile(t, "testdata/living.ged")
+	p := privacyPolicy(RedactPrivate)
+
+	boolTestCases{
+		{"Born before the lifespan", false, ProbablyLiving(person(t, g, "I1"), p)},
+		{"Married before the lifespan", false, ProbablyLiving(person(t, g, "I2"), p)},
+		{"Parent born long ago", false, ProbablyLiving(person(t, g, "I3"), p)},
+		{"Child born long ago", false, ProbablyLiving(person(t, g, "I4"), p)},
+		{"Born within the lifespan", true, ProbablyLiving(person(t, g, "I6"), p)},
+		{"Died", false, ProbablyLiving(person(t, g, "I8"), p)},
+	}.run(t)
+}
+
+func TestPrivatizeRedact(t *testing.T) {
+	g := decodeFile(t, "testdata/living.ged")
+	pg := Privatize(g, privacyPolicy(RedactPrivate))
+
+	stringTestCases{
+		{"Individuals", "I1 I2 I3 I4 I5 I6 I7 I8", xrefs(pg.Individual)},
+		{"Families", "F1 F2 F3", familyXrefs(pg.Family)},
+		{"I6 name", "Living", person(t, pg, "I6").Name[0].Name},
+		{"I8 name", "Living", person(t, pg, "I8").Name[0].Name},
+		{"I6 sex", "M", person(t, pg, "I6").Sex},
+		{"F3 children", "I6", xrefs(family(t, pg, "F3").Children())},
+		{"Original I6 name", "Peter /Hart/", person(t, g, "I6").Name[0].Name},
+		{"Original F2 restriction", "privacy", family(t, g, "F2").Restriction},
+	}.run(t)
+
+	intTestCases{
+		{"I1 event count was [%d]", 1, len(person(t, pg, "I1").Event)},
+		{"I6 event count was [%d]", 0, len(person(t, pg, "I6").Event)},
+		{"I6 note count was [%d]", 0, len(person(t, pg, "I6").Note)},
+		{"F1 event count was [%d]", 1, len(family(t, pg, "F1").Event)},
+		{"F2 event count was [%d]", 0, len(family(t, pg, "F2").Event)},
+		{"F2 note count was [%d]", 0, len(family(t, pg, "F2").Note)},
+		{"Original F2 event count was [%d]", 1, len(family(t, g, "F2").Event)},
+		{"F3 event count was [%d]", 0, len(family(t, pg, "F3").Event)},
+		{"Source count was [%d]", 0, len(pg.Source)},
+		{"Repository count was [%d]", 0, len(pg.Repository)},
+		{"Note count was [%d]", 0, len(pg.Note)},
+		{"Original note count was [%d]", 1, len(g.Note)},
+	}.run(t)
+}
+
+func TestPrivatizeRemove(t *testing.T) {
+	g := decodeFile(t, "testdata/living.ged")
+	pg := Privatize(g, privacyPolicy(RemovePrivate))
+
+	stringTestCases{
+		{"Individuals", "I1 I2 I3 I4 I5", xrefs(pg.Individual)},
+		{"Families", "F1 F2", familyXrefs(pg.Family)},
+		{"F2 children", "I5", xrefs(family(t, pg, "F2").Children())},
+	}.run(t)
+
+	intTestCases{
+		{"Original individual count was [%d]", 8, len(g.Individual)},
+		{"Note count was [%d]", 0, len(pg.Note)},
+	}.run(t)
+}
@@ -0,0 +1,541 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// queryKinds are the fields of Gedcom holding the records of each kind a
+// query can select.
+var queryKinds = map[string]string{
+	"INDI": "Individual",
+	"FAM":  "Family",
+	"SOUR": "Source",
+	"REPO": "Repository",
+	"NOTE": "Note",
+	"OBJE": "Object",
+}
+
+// Query is a parsed query selecting records of one kind, such as
+//
+//	INDI[BIRT.DATE < 1850 and BIRT.PLAC ~ "Ireland"]
+//	FAM[count(CHIL) > 10]
+//
+// A query is the tag of a kind of record, INDI, FAM, SOUR, REPO, NOTE or
+// OBJE, followed by an optional condition in brackets that the records must
+// meet. Conditions compare paths, quoted strings, numbers and counts with
+// =, !=, <, <=, >, >= and ~, and are combined with and, or, not and
+// parentheses. A path on its own is true if the record has anything at that
+// path.
+//
+// A path is a list of tags separated by dots, such as BIRT.DATE, giving the
+// values of the record at that path, as in a GEDCOM file. Pointers to other
+// records are followed, so that FAM[HUSB.NAME ~ "Grant"] selects families
+// whose husband is a Grant and INDI[FAMS.MARR.DATE < 1800] people married
+// before 1800. count(path) is the number of values at the path. A single
+// word without dots on the right of a comparison is not a path but a string,
+// so that INDI[SEX = M] selects men; to compare with the values at a
+// one-tag path, put the path on the left.
+//
+// A comparison is true if any value on the left compares as given with any
+// value on the right, except that != is true if none compare equal. Values
+// that are both numbers are compared as numbers, and values that are both
+// dates as the ranges of days they cover: two dates are equal if they could
+// be the same day and one is before another if every day it could be is
+// before every day the other could be. Other values are compared as text,
+// ignoring case. a ~ b is true if a contains b, ignoring case.
+type Query struct {
+	kind  string
+	where queryCondition // nil to select every record
+}
+
+// ParseQuery parses the query s.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{lexer: &queryLexer{input: s}}
+	p.advance()
+
+	if p.tok.kind != queryIdent {
+		return nil, p.errorf("expected a kind of record")
+	}
+	q := &Query{kind: p.tok.text}
+	if _, ok := queryKinds[q.kind]; !ok {
+		return nil, p.errorf("unknown kind of record %q", q.kind)
+	}
+	p.advance()
+
+	if p.tok.kind == queryLeftBracket {
+		p.advance()
+		q.where = p.or()
+		p.expect(queryRightBracket, "]")
+	}
+	p.expect(queryEOF, "end of query")
+	if p.err != nil {
+		return nil, p.err
+	}
+	return q, nil
+}
+
+// Select parses and runs the query s on g.
+func Select(g *Gedcom, s string) (interface{}, error) {
+	q, err := ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	return q.Run(g), nil
+}
+
+// Run returns the records of g selected by q, in the order of the file, as a
+// slice of their type, such as []*IndividualRecord for INDI or
+// []*FamilyRecord for FAM.
+func (q *Query) Run(g *Gedcom) interface{} {
+	list := reflect.ValueOf(g).Elem().FieldByName(queryKinds[q.kind])
+	selected := reflect.MakeSlice(list.Type(), 0, 0)
+	for i := 0; i < list.Len(); i++ {
+		rec := list.Index(i)
+		if !rec.IsNil() && (q.where == nil || q.where.test(rec)) {
+			selected = reflect.Append(selected, rec)
+		}
+	}
+	return selected.Interface()
+}
+
+// queryCondition is a condition a record may meet.
+type queryCondition interface {
+	test(rec reflect.Value) bool
+}
+
+// queryOperand is a path, count or literal giving values to compare.
+type queryOperand interface {
+	values(rec reflect.Value) []string
+}
+
+type queryAnd struct{ a, b queryCondition }
+type queryOr struct{ a, b queryCondition }
+type queryNot struct{ a queryCondition }
+
+func (c queryAnd) test(rec reflect.Value) bool { return c.a.test(rec) && c.b.test(rec) }
+func (c queryOr) test(rec reflect.Value) bool  { return c.a.test(rec) || c.b.test(rec) }
+func (c queryNot) test(rec reflect.Value) bool { return !c.a.test(rec) }
+
+// queryCompare compares the values of two operands.
+type queryCompare struct {
+	left, right queryOperand
+	op          string
+}
+
+func (c queryCompare) test(rec reflect.Value) bool {
+	if c.op == "!=" {
+		return !queryCompare{c.left, c.right, "="}.test(rec)
+	}
+	right := c.right.values(rec)
+	for _, a := range c.left.values(rec) {
+		for _, b := range right {
+			if compareQueryValues(a, b, c.op) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareQueryValues reports whether a op b.
+func compareQueryValues(a string, b string, op string) bool {
+	if op == "~" {
+		return strings.Contains(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	var cmp int
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	} else if ra, rb, ok := queryDates(a, b); ok {
+		switch op {
+		case "=":
+			return ra.Overlaps(rb)
+		case "<":
+			return definitelyBefore(ra, rb)
+		case ">":
+			return definitelyBefore(rb, ra)
+		case "<=":
+			return !definitelyBefore(rb, ra)
+		case ">=":
+			return !definitelyBefore(ra, rb)
+		}
+	} else {
+		cmp = strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// queryDates returns the ranges of a and b if both are dates.
+func queryDates(a string, b string) (DateRange, DateRange, bool) {
+	ra, err := ParseDateRange(a)
+	if err != nil {
+		return DateRange{}, DateRange{}, false
+	}
+	rb, err := ParseDateRange(b)
+	if err != nil {
+		return DateRange{}, DateRange{}, false
+	}
+	return ra, rb, true
+}
+
+// queryExists is true if a record has anything at a path.
+type queryExists struct{ path queryPath }
+
+func (c queryExists) test(rec reflect.Value) bool { return len(c.path.nodes(rec)) > 0 }
+
+// queryLiteral is a quoted string or number.
+type queryLiteral string
+
+func (l queryLiteral) values(rec reflect.Value) []string { return []string{string(l)} }
+
+// queryCount is the number of values at a path.
+type queryCount struct{ path queryPath }
+
+func (c queryCount) values(rec reflect.Value) []string {
+	return []string{strconv.Itoa(len(c.path.nodes(rec)))}
+}
+
+// queryPath is a list of tags leading from a record to some of its values.
+type queryPath []string
+
+func (p queryPath) values(rec reflect.Value) []string {
+	nodes := p.nodes(rec)
+	values := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		values = append(values, queryValue(n))
+	}
+	return values
+}
+
+// nodes returns the fields and list elements found at the path from rec.
+func (p queryPath) nodes(rec reflect.Value) []reflect.Value {
+	nodes := []reflect.Value{rec}
+	for _, tag := range p {
+		var next []reflect.Value
+		for _, n := range nodes {
+			next = append(next, queryChildren(n, tag)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// queryChildren returns the substructures of v with tag that are not empty.
+// If v has none and its own value is a pointer to a record, such as a
+// child's or a family link's, those of that record are returned instead.
+func queryChildren(v reflect.Value, tag string) []reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var children []reflect.Value
+	for _, f := range diffStructures[v.Type()].fields {
+		field := v.FieldByName(f.name)
+		if field.Kind() != reflect.Slice {
+			if f.tag == tag && len(flattenValue(field, tag, false, nil, nil)) > 0 {
+				children = append(children, field)
+			}
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			e := field.Index(i)
+			if e.Kind() == reflect.Ptr && e.IsNil() {
+				continue
+			}
+			t := f.tag
+			if t == "" {
+				t = reflect.Indirect(e).FieldByName("Tag").String()
+			}
+			if t == tag {
+				children = append(children, e)
+			}
+		}
+	}
+
+	if name := diffStructures[v.Type()].value; len(children) == 0 && name != "" {
+		if own := v.FieldByName(name); own.Kind() == reflect.Ptr && !own.IsNil() && recordXref(own) != "" {
+			return queryChildren(own, tag)
+		}
+	}
+	return children
+}
+
+// queryValue returns the value of a field or list element: a string, a
+// pointer to a record such as @I1@, or the value of a structure.
+func queryValue(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String()
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		return ""
+	case v.Kind() == reflect.Ptr && recordXref(v) != "":
+		return pointerValue(v, nil)
+	case v.Kind() == reflect.Ptr:
+		v = v.Elem()
+	}
+	if name := diffStructures[v.Type()].value; v.Kind() == reflect.Struct && name != "" {
+		return scalarValue(v.FieldByName(name), nil)
+	}
+	return ""
+}
+
+// queryParser is a recursive descent parser of queries. The first error
+// found is kept in err, after which the parser produces nothing of use.
+type queryParser struct {
+	lexer *queryLexer
+	tok   queryToken
+	err   error
+}
+
+func (p *queryParser) advance() {
+	p.tok = p.lexer.next()
+	if p.tok.kind == queryError && p.err == nil {
+		p.err = fmt.Errorf("query: position %d: %s", p.tok.pos+1, p.tok.text)
+	}
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	if p.err == nil {
+		p.err = fmt.Errorf("query: position %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+	}
+	return p.err
+}
+
+func (p *queryParser) expect(kind queryTokenKind, what string) {
+	if p.tok.kind != kind {
+		p.errorf("expected %s", what)
+		return
+	}
+	p.advance()
+}
+
+// keyword reports whether the current token is the keyword word, in any
+// case, and if so moves past it.
+func (p *queryParser) keyword(word string) bool {
+	if p.tok.kind == queryIdent && strings.EqualFold(p.tok.text, word) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) or() queryCondition {
+	c := p.and()
+	for p.err == nil && p.keyword("or") {
+		c = queryOr{c, p.and()}
+	}
+	return c
+}
+
+func (p *queryParser) and() queryCondition {
+	c := p.not()
+	for p.err == nil && p.keyword("and") {
+		c = queryAnd{c, p.not()}
+	}
+	return c
+}
+
+func (p *queryParser) not() queryCondition {
+	if p.keyword("not") {
+		return queryNot{p.not()}
+	}
+	return p.comparison()
+}
+
+func (p *queryParser) comparison() queryCondition {
+	if p.tok.kind == queryLeftParen {
+		p.advance()
+		c := p.or()
+		p.expect(queryRightParen, ")")
+		return c
+	}
+
+	left := p.operand(false)
+	if p.tok.kind != queryOperator {
+		if path, ok := left.(queryPath); ok {
+			return queryExists{path}
+		}
+		p.errorf("expected a comparison")
+		return nil
+	}
+	op := p.tok.text
+	p.advance()
+	return queryCompare{left, p.operand(true), op}
+}
+
+// operand parses a path, string, number or count. A single word on the
+// right of a comparison is a string.
+func (p *queryParser) operand(right bool) queryOperand {
+	switch tok := p.tok; tok.kind {
+	case queryString, queryNumber:
+		p.advance()
+		return queryLiteral(tok.text)
+	case queryIdent:
+		p.advance()
+		if strings.EqualFold(tok.text, "count") && p.tok.kind == queryLeftParen {
+			p.advance()
+			path := p.path()
+			p.expect(queryRightParen, ")")
+			return queryCount{path}
+		}
+		if right && p.tok.kind != queryDot {
+			return queryLiteral(tok.text)
+		}
+		return p.pathFrom(tok.text)
+	}
+	p.errorf("expected a path, string or number")
+	return nil
+}
+
+func (p *queryParser) path() queryPath {
+	if p.tok.kind != queryIdent {
+		p.errorf("expected a path")
+		return nil
+	}
+	first := p.tok.text
+	p.advance()
+	return p.pathFrom(first)
+}
+
+// pathFrom parses the rest of a path beginning with the tag first.
+func (p *queryParser) pathFrom(first string) queryPath {
+	path := queryPath{first}
+	for p.err == nil && p.tok.kind == queryDot {
+		p.advance()
+		if p.tok.kind != queryIdent {
+			p.errorf("expected a tag")
+			return nil
+		}
+		path = append(path, p.tok.text)
+		p.advance()
+	}
+	return path
+}
+
+// queryTokenKind is a kind of token of the query language.
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryError
+	queryIdent
+	queryString
+	queryNumber
+	queryOperator
+	queryDot
+	queryLeftBracket
+	queryRightBracket
+	queryLeftParen
+	queryRightParen
+)
+
+// queryToken is a token of the query language and its position in the
+// query. The text of a string is unquoted, and of an error is its message.
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// queryLexer splits a query into tokens.
+type queryLexer struct {
+	input string
+	pos   int
+}
+
+var queryPunctuation = map[byte]queryTokenKind{
+	'.': queryDot, '[': queryLeftBracket, ']': queryRightBracket, '(': queryLeftParen, ')': queryRightParen,
+}
+
+func (l *queryLexer) next() queryToken {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos == len(l.input) {
+		return queryToken{queryEOF, "", start}
+	}
+
+	c := l.input[l.pos]
+	switch {
+	case queryPunctuation[c] != 0:
+		l.pos++
+		return queryToken{queryPunctuation[c], string(c), start}
+	case strings.IndexByte("=!<>~", c) >= 0:
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' && c != '=' && c != '~' {
+			l.pos++
+		}
+		op := l.input[start:l.pos]
+		if op == "!" {
+			return queryToken{queryError, "expected !=", start}
+		}
+		return queryToken{queryOperator, op, start}
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.input) && l.input[l.pos] != '"' {
+			if l.input[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			return queryToken{queryError, "unterminated string", start}
+		}
+		l.pos++
+		s, err := strconv.Unquote(l.input[start:l.pos])
+		if err != nil {
+			return queryToken{queryError, "invalid string", start}
+		}
+		return queryToken{queryString, s, start}
+	case c >= '0' && c <= '9':
+		for l.pos < len(l.input) && (l.input[l.pos] >= '0' && l.input[l.pos] <= '9' || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		return queryToken{queryNumber, l.input[start:l.pos], start}
+	case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		for l.pos < len(l.input) && isQueryIdentByte(l.input[l.pos]) {
+			l.pos++
+		}
+		return queryToken{queryIdent, l.input[start:l.pos], start}
+	}
+	return queryToken{queryError, fmt.Sprintf("unexpected %q", c), start}
+}
+
+func isQueryIdentByte(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,82 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestQueryIndividuals(t *testing.T) {
+	g := decodeFile(t, "testdata/names.ged")
+	query := func(s string) string {
+		r, err := Select(g, s)
+		if err != nil {
+			t.Fatalf("Select(%q) gave error %v, expected no error", s, err)
+		}
+		return xrefs(r.([]*IndividualRecord))
+	}
+
+	stringTestCases{
+		{"Every individual", "I1 I2 I3 I4 I5 I6 I7", query(`INDI`)},
+		{"Date and place", "I7", query(`INDI[BIRT.DATE < 1850 and BIRT.PLAC ~ "Ireland"]`)},
+		{"Date within a year", "I2", query(`INDI[BIRT.DATE = 1855]`)},
+		{"Number compared with a year", "I4 I6", query(`INDI[BIRT.DATE >= 1880]`)},
+		{"Missing event", "I5", query(`INDI[not BIRT]`)},
+		{"Count", "I2", query(`INDI[count(NAME) > 1]`)},
+		{"Alternate name", "I2 I7", query(`INDI[NAME ~ "kelly"]`)},
+		{"Or", "I4 I5", query(`INDI[RESI.PLAC ~ "york" or BIRT.PLAC = "lodz, poland"]`)},
+	}.run(t)
+}
+
+func TestQueryFamilies(t *testing.T) {
+	g := decodeFile(t, "testdata/pedigree.ged")
+	query := func(s string) []*FamilyRecord {
+		r, err := Select(g, s)
+		if err != nil {
+			t.Fatalf("Select(%q) gave error %v, expected no error", s, err)
+		}
+		return r.([]*FamilyRecord)
+	}
+	people := func(s string) []*IndividualRecord {
+		r, err := Select(g, s)
+		if err != nil {
+			t.Fatalf("Select(%q) gave error %v, expected no error", s, err)
+		}
+		return r.([]*IndividualRecord)
+	}
+
+	stringTestCases{
+		{"Count of children", "F1 F4", familyXrefs(query(`FAM[count(CHIL) > 1]`))},
+		{"Husband's name", "F1 F2 F4", familyXrefs(query(`FAM[HUSB.NAME ~ "Grant"]`))},
+		{"No wife", "F5 F6", familyXrefs(query(`FAM[not WIFE]`))},
+		{"Children's births", "F4 F7", familyXrefs(query(`FAM[CHIL.BIRT.DATE > 1880]`))},
+		{"Marriage through family links", "I1 I2", xrefs(people(`INDI[FAMS.MARR.DATE < 1850]`))},
+		{"Not equal", "I2 I4 I5 I8 I10 I11", xrefs(people(`INDI[SEX != "M"]`))},
+		{"Unquoted word", "I1 I3 I6 I7 I9 I12 I13 I14 I15", xrefs(people(`INDI[SEX = M]`))},
+		{"Paths on both sides", "F1 F4", familyXrefs(query(`FAM[HUSB.BIRT.DATE < WIFE.BIRT.DATE]`))},
+		{"Parentheses", "I3", xrefs(people(`INDI[FAMC.HUSB.NAME ~ "grant" and (DEAT or BIRT.DATE < 1831)]`))},
+	}.run(t)
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	errorText := func(s string) string {
+		_, err := ParseQuery(s)
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+
+	stringTestCases{
+		{"Unknown kind", `query: position 1: unknown kind of record "PERSON"`, errorText(`PERSON[NAME]`)},
+		{"Missing operand", "query: position 17: expected a path, string or number", errorText(`INDI[BIRT.DATE <]`)},
+		{"Unterminated string", "query: position 13: unterminated string", errorText(`INDI[NAME = "x]`)},
+		{"Unclosed parenthesis", "query: position 11: expected )", errorText(`INDI[(BIRT]`)},
+		{"Missing tag", "query: position 11: expected a tag", errorText(`INDI[BIRT.]`)},
+		{"Literal without comparison", "query: position 9: expected a comparison", errorText(`INDI["x"]`)},
+		{"Trailing text", "query: position 12: expected end of query", errorText(`INDI[BIRT] x`)},
+		{"Empty query", "query: position 1: expected a kind of record", errorText(``)},
+	}.run(t)
+}
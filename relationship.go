@@ -0,0 +1,386 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// relationshipGenerations limits how far back Relationship.Paths seeks
+// common ancestors.
+const relationshipGenerations = 25
+
+// Relationship describes how one individual is related to another.
+type Relationship struct {
+	// Term names what the second individual is to the first, such as
+	// "2nd cousin once removed", "half-brother" or "mother-in-law". It is
+	// empty if no relationship was found.
+	Term string
+
+	// Coefficient is the coefficient of relationship, the sum of 1/2 raised to
+	// the length of each path through a common ancestor. It is zero for
+	// relationships by marriage or adoption.
+	Coefficient float64
+
+	// CommonAncestors are the nearest common ancestors, those reached from
+	// each individual without passing through another common ancestor.
+	CommonAncestors []*IndividualRecord
+
+	a, b *IndividualRecord
+}
+
+// RelationshipPath is one line of blood relationship through a common ancestor.
+type RelationshipPath struct {
+	Ancestor *IndividualRecord
+	FromA    []*IndividualRecord // from the first individual up to Ancestor
+	FromB    []*IndividualRecord // from the second individual up to Ancestor
+}
+
+// FindRelationship works out what b is to a. Blood relationships are found
+// through common ancestors along birth links, naming the relationship through
+// the closest of them. Failing that, relationships by marriage and adoption
+// such as spouse, step-mother or brother-in-law are recognised.
+func FindRelationship(a *IndividualRecord, b *IndividualRecord) *Relationship {
+	r := &Relationship{a: a, b: b}
+	if a == b {
+		r.Term = "self"
+		r.Coefficient = 1
+		return r
+	}
+
+	p := newBirthPedigree(a, b)
+	k := &kinship{pedigree: p, memo: make(map[[2]*IndividualRecord]float64), outbred: true}
+	r.Coefficient = 2 * k.coefficient(a, b)
+
+	la, lb := p.ancestorLines(a), p.ancestorLines(b)
+	common := make(map[*IndividualRecord]bool)
+	var best *IndividualRecord
+	for _, x := range la.order {
+		if _, ok := lb.generation[x]; !ok {
+			continue
+		}
+		common[x] = true
+		if best == nil || la.generation[x]+lb.generation[x] < la.generation[best]+lb.generation[best] {
+			best = x
+		}
+	}
+	if best == nil {
+		r.Term = affinityTerm(a, b)
+		return r
+	}
+
+	nearB := p.reachedBefore(b, common)
+	for _, x := range p.reachedBefore(a, common).order {
+		if _, ok := nearB.generation[x]; ok {
+			r.CommonAncestors = append(r.CommonAncestors, x)
+		}
+	}
+
+	path := &RelationshipPath{Ancestor: best, FromA: la.lineTo(best), FromB: lb.lineTo(best)}
+	r.Term = bloodTerm(len(path.FromA)-1, len(path.FromB)-1, isHalfPath(path), b.Sex)
+	return r
+}
+
+// String returns the relationship term.
+func (r *Relationship) String() string {
+	return r.Term
+}
+
+// Paths returns every pair of birth lines from the two individuals that meet
+// at a common ancestor without sharing anyone else, up to 25 generations
+// back. There may be very many when a pedigree collapses, so they are only
+// listed when asked for.
+func (r *Relationship) Paths() []*RelationshipPath {
+	if r.a == nil || r.b == nil || r.a == r.b {
+		return nil
+	}
+	return commonAncestorPaths(r.a, r.b)
+}
+
+// ancestorLines are the shortest lines from a person up to their ancestors.
+type ancestorLines struct {
+	order      []*IndividualRecord // the person and their ancestors, nearest first
+	generation map[*IndividualRecord]int
+	via        map[*IndividualRecord]*IndividualRecord // the child through whom each ancestor was reached
+}
+
+// ancestorLines finds the shortest line from ind to each of their ancestors
+// in the pedigree.
+func (p *birthPedigree) ancestorLines(ind *IndividualRecord) *ancestorLines {
+	return p.reachedBefore(ind, nil)
+}
+
+// reachedBefore finds the shortest line from ind to each of their ancestors in
+// the pedigree, without going beyond anyone in stop other than ind.
+func (p *birthPedigree) reachedBefore(ind *IndividualRecord, stop map[*IndividualRecord]bool) *ancestorLines {
+	l := &ancestorLines{
+		order:      []*IndividualRecord{ind},
+		generation: map[*IndividualRecord]int{ind: 0},
+		via:        make(map[*IndividualRecord]*IndividualRecord),
+	}
+	for n := 0; n < len(l.order); n++ {
+		child := l.order[n]
+		if child != ind && stop[child] {
+			continue
+		}
+		for _, parent := range p.parents[child] {
+			if _, seen := l.generation[parent]; parent == nil || seen {
+				continue
+			}
+			l.generation[parent] = l.generation[child] + 1
+			l.via[parent] = child
+			l.order = append(l.order, parent)
+		}
+	}
+	return l
+}
+
+// lineTo returns the shortest line from the person up to their ancestor x.
+func (l *ancestorLines) lineTo(x *IndividualRecord) []*IndividualRecord {
+	line := make([]*IndividualRecord, l.generation[x]+1)
+	for n := len(line) - 1; n >= 0; n-- {
+		line[n] = x
+		x = l.via[x]
+	}
+	return line
+}
+
+// commonAncestorPaths returns every pair of birth lines from a and b that
+// meet at a common ancestor without sharing anyone else.
+func commonAncestorPaths(a *IndividualRecord, b *IndividualRecord) []*RelationshipPath {
+	opts := &TraversalOptions{Pedigree: []string{"birth"}}
+
+	fromA := make(map[*IndividualRecord][]*LineageStep)
+	for _, s := range Ancestors(a, relationshipGenerations, opts) {
+		fromA[s.Person] = append(fromA[s.Person], s)
+	}
+
+	var paths []*RelationshipPath
+	for _, sb := range Ancestors(b, relationshipGenerations, opts) {
+		for _, sa := range fromA[sb.Person] {
+			if !disjointPaths(sa.Path, sb.Path) {
+				continue
+			}
+			paths = append(paths, &RelationshipPath{
+				Ancestor: sb.Person,
+				FromA:    sa.Path,
+				FromB:    sb.Path,
+			})
+		}
+	}
+	return paths
+}
+
+// disjointPaths reports whether two paths ending at the same person share
+// nobody else.
+func disjointPaths(p []*IndividualRecord, q []*IndividualRecord) bool {
+	for _, x := range p[:len(p)-1] {
+		if onPath(q[:len(q)-1], x) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHalfPath reports whether the path runs through only one of a couple, as it
+// does for half-siblings, because the children of the common ancestor on each
+// side have different other parents.
+func isHalfPath(p *RelationshipPath) bool {
+	if len(p.FromA) < 2 || len(p.FromB) < 2 {
+		return false
+	}
+	x := p.Ancestor
+	other := func(child *IndividualRecord) *IndividualRecord {
+		for _, parent := range []*IndividualRecord{child.Father(), child.Mother()} {
+			if parent != nil && parent != x {
+				return parent
+			}
+		}
+		return nil
+	}
+	oa := other(p.FromA[len(p.FromA)-2])
+	ob := other(p.FromB[len(p.FromB)-2])
+	return oa != nil && ob != nil && oa != ob
+}
+
+// gendered picks the male, female or neutral word according to sex.
+func gendered(sex string, male string, female string, neutral string) string {
+	switch strings.ToUpper(sex) {
+	case "M":
+		return male
+	case "F":
+		return female
+	}
+	return neutral
+}
+
+func greats(n int) string {
+	return strings.Repeat("great-", n)
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+func removed(n int) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return " once removed"
+	case 2:
+		return " twice removed"
+	}
+	return fmt.Sprintf(" %d times removed", n)
+}
+
+// bloodTerm names the relationship of b to a, where a is up generations below
+// the common ancestor and b is down generations below it.
+func bloodTerm(up int, down int, half bool, sex string) string {
+	prefix := ""
+	if half {
+		prefix = "half-"
+	}
+
+	switch {
+	case down == 0:
+		switch up {
+		case 1:
+			return gendered(sex, "father", "mother", "parent")
+		case 2:
+			return gendered(sex, "grandfather", "grandmother", "grandparent")
+		}
+		return greats(up-2) + gendered(sex, "grandfather", "grandmother", "grandparent")
+	case up == 0:
+		switch down {
+		case 1:
+			return gendered(sex, "son", "daughter", "child")
+		case 2:
+			return gendered(sex, "grandson", "granddaughter", "grandchild")
+		}
+		return greats(down-2) + gendered(sex, "grandson", "granddaughter", "grandchild")
+	case up == 1 && down == 1:
+		return prefix + gendered(sex, "brother", "sister", "sibling")
+	case down == 1:
+		word := gendered(sex, "uncle", "aunt", "aunt or uncle")
+		if up == 2 {
+			return prefix + word
+		}
+		return prefix + greats(up-3) + "grand-" + word
+	case up == 1:
+		word := gendered(sex, "nephew", "niece", "niece or nephew")
+		if down == 2 {
+			return prefix + word
+		}
+		return prefix + greats(down-3) + "grand-" + word
+	}
+
+	degree := up
+	if down < degree {
+		degree = down
+	}
+	diff := up - down
+	if diff < 0 {
+		diff = -diff
+	}
+	return prefix + ordinal(degree-1) + " cousin" + removed(diff)
+}
+
+// affinityTerm names relationships of b to a by marriage or adoption.
+func affinityTerm(a *IndividualRecord, b *IndividualRecord) string {
+	sex := b.Sex
+
+	spouses := a.Spouses()
+	if onPath(spouses, b) {
+		return gendered(sex, "husband", "wife", "spouse")
+	}
+
+	if onPath(adoptiveParents(a), b) {
+		return gendered(sex, "adoptive father", "adoptive mother", "adoptive parent")
+	}
+	if onPath(adoptiveParents(b), a) {
+		return gendered(sex, "adopted son", "adopted daughter", "adopted child")
+	}
+
+	parents := []*IndividualRecord{a.Father(), a.Mother()}
+	for _, p := range parents {
+		if p != nil && onPath(p.Spouses(), b) && !onPath(parents, b) {
+			return gendered(sex, "step-father", "step-mother", "step-parent")
+		}
+	}
+
+	for _, s := range spouses {
+		if onPath(s.Children(), b) && !onPath(a.Children(), b) {
+			return gendered(sex, "stepson", "stepdaughter", "stepchild")
+		}
+	}
+
+	for _, p := range parents {
+		if p == nil {
+			continue
+		}
+		for _, sp := range p.Spouses() {
+			if onPath(parents, sp) {
+				continue
+			}
+			if onPath(sp.Children(), b) && b.Father() != p && b.Mother() != p {
+				return gendered(sex, "step-brother", "step-sister", "step-sibling")
+			}
+		}
+	}
+
+	for _, s := range spouses {
+		if s.Father() == b || s.Mother() == b {
+			return gendered(sex, "father-in-law", "mother-in-law", "parent-in-law")
+		}
+		if onPath(s.Siblings(), b) {
+			return gendered(sex, "brother-in-law", "sister-in-law", "sibling-in-law")
+		}
+	}
+
+	for _, s := range a.Siblings() {
+		if onPath(s.Spouses(), b) {
+			return gendered(sex, "brother-in-law", "sister-in-law", "sibling-in-law")
+		}
+	}
+
+	for _, c := range a.Children() {
+		if onPath(c.Spouses(), b) {
+			return gendered(sex, "son-in-law", "daughter-in-law", "child-in-law")
+		}
+	}
+
+	return ""
+}
+
+// adoptiveParents returns the parents of ind other than by birth, whether the
+// link to the family or the relationship to that parent is not by birth.
+func adoptiveParents(ind *IndividualRecord) []*IndividualRecord {
+	var parents []*IndividualRecord
+	for _, l := range ind.Parents {
+		if l.Family == nil {
+			continue
+		}
+		if !isBirthLink(ind, l.Family, true) {
+			parents = appendPerson(parents, l.Family.Husband)
+		}
+		if !isBirthLink(ind, l.Family, false) {
+			parents = appendPerson(parents, l.Family.Wife)
+		}
+	}
+	return parents
+}
@@ -0,0 +1,112 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestFindRelationship(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	rel := func(a, b string) *Relationship {
+		return FindRelationship(person(t, pg, a), person(t, pg, b))
+	}
+
+	stringTestCases{
+		{"I9 to I7", "father", rel("I9", "I7").Term},
+		{"I7 to I9", "son", rel("I7", "I9").Term},
+		{"I9 to I1", "great-grandfather", rel("I9", "I1").Term},
+		{"I1 to I9", "great-grandson", rel("I1", "I9").Term},
+		{"I3 to I4", "sister", rel("I3", "I4").Term},
+		{"I3 to I15", "half-brother", rel("I3", "I15").Term},
+		{"I7 to I8", "1st cousin", rel("I7", "I8").Term},
+		{"I7 to I4", "aunt", rel("I7", "I4").Term},
+		{"I4 to I7", "nephew", rel("I4", "I7").Term},
+		{"I9 to I4 through the closer line", "grandmother", rel("I9", "I4").Term},
+		{"I9 to I15", "half-grand-uncle", rel("I9", "I15").Term},
+		{"I3 to I5", "wife", rel("I3", "I5").Term},
+		{"I15 to I1", "step-father", rel("I15", "I1").Term},
+		{"I5 to I1", "father-in-law", rel("I5", "I1").Term},
+		{"I3 to I6", "brother-in-law", rel("I3", "I6").Term},
+		{"I1 to I5", "daughter-in-law", rel("I1", "I5").Term},
+		{"I9 to I10", "", rel("I9", "I10").Term},
+		{"I7 to I10", "adopted daughter", rel("I7", "I10").Term},
+		{"I10 to I8", "adoptive mother", rel("I10", "I8").Term},
+		{"I9 to I9", "self", rel("I9", "I9").Term},
+	}.run(t)
+
+	cousins := rel("I7", "I8")
+	siblings := rel("I3", "I4")
+	collapsed := rel("I9", "I4")
+
+	intTestCases{
+		{"Cousin path count was [%d]", 2, len(cousins.Paths())},
+		{"Cousin common ancestor count was [%d]", 2, len(cousins.CommonAncestors)},
+		{"Collapsed path count was [%d]", 3, len(collapsed.Paths())},
+		{"Collapsed common ancestor count was [%d]", 3, len(collapsed.CommonAncestors)},
+		{"Father common ancestor count was [%d]", 1, len(rel("I9", "I7").CommonAncestors)},
+	}.run(t)
+
+	var coefficientTests = []struct {
+		name    string
+		r       *Relationship
+		correct float64
+	}{
+		{"first cousins", cousins, 0.125},
+		{"siblings", siblings, 0.5},
+		{"half-siblings", rel("I3", "I15"), 0.25},
+		{"grandmother and aunt", collapsed, 0.375},
+		{"spouses", rel("I3", "I5"), 0},
+	}
+
+	for _, tt := range coefficientTests {
+		if tt.r.Coefficient != tt.correct {
+			t.Errorf("Coefficient of %s was [%v], expected [%v]", tt.name, tt.r.Coefficient, tt.correct)
+		}
+	}
+}
+
+func TestFindRelationshipCollapsed(t *testing.T) {
+
+	f := brotherSisterMatings(40)
+	r := FindRelationship(f.Husband, f.Wife)
+
+	stringTestCases{
+		{"Brother to sister", "sister", r.Term},
+		{"Common ancestors", "H39 W39", xrefs(r.CommonAncestors)},
+	}.run(t)
+
+	// Full siblings whose parents are themselves full siblings, and so on.
+	if r.Coefficient <= 0.5 || r.Coefficient >= 1 {
+		t.Errorf("Coefficient was [%v], expected between 0.5 and 1", r.Coefficient)
+	}
+}
+
+func TestFindRelationshipAdoptedByRelation(t *testing.T) {
+
+	ag := decodeFile(t, "testdata/allged.ged")
+
+	// PERSON7 is the natural child of PERSON8 but the adopted child of her
+	// husband PERSON1.
+	stringTestCases{
+		{"PERSON7 to PERSON1", "adoptive father", FindRelationship(person(t, ag, "PERSON7"), person(t, ag, "PERSON1")).Term},
+		{"PERSON1 to PERSON7", "adopted child", FindRelationship(person(t, ag, "PERSON1"), person(t, ag, "PERSON7")).Term},
+		{"PERSON7 to PERSON8", "mother", FindRelationship(person(t, ag, "PERSON7"), person(t, ag, "PERSON8")).Term},
+	}.run(t)
+}
+
+func TestBloodTerms(t *testing.T) {
+
+	stringTestCases{
+		{"Second cousin once removed", "2nd cousin once removed", bloodTerm(3, 4, false, "F")},
+		{"Third cousin twice removed", "3rd cousin twice removed", bloodTerm(6, 4, false, "M")},
+		{"Eleventh cousin", "11th cousin", bloodTerm(12, 12, false, "M")},
+		{"Half first cousin", "half-1st cousin", bloodTerm(2, 2, true, "M")},
+		{"Great-grand-uncle", "great-grand-uncle", bloodTerm(4, 1, false, "M")},
+		{"Grand-niece", "grand-niece", bloodTerm(1, 3, false, "F")},
+		{"Great-great-grandparent", "great-great-grandparent", bloodTerm(4, 0, false, "U")},
+	}.run(t)
+}
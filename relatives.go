@@ -0,0 +1,170 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+)
+
+// isBirthPedigree reports whether a PEDI value describes a biological link.
+// A missing pedigree is taken to mean birth, as the GEDCOM specification does.
+func isBirthPedigree(pedigree string) bool {
+	switch strings.ToLower(pedigree) {
+	case "", "birth", "natural":
+		return true
+	}
+	return false
+}
+
+// isBirthLink reports whether child is the birth child of the husband of
+// family f, or of the wife if husband is false, according to both the PEDI of
+// the child's link to f and the _FREL or _MREL of the family's link to the
+// child.
+func isBirthLink(child *IndividualRecord, f *FamilyRecord, husband bool) bool {
+	if !isBirthPedigree(childPedigree(child, f)) {
+		return false
+	}
+	c := f.childRecord(child)
+	switch {
+	case c == nil:
+		return true
+	case husband:
+		return isBirthPedigree(c.FatherRelation)
+	}
+	return isBirthPedigree(c.MotherRelation)
+}
+
+// ParentFamilies returns the families in which the individual is a child. If
+// pedigrees are given, such as "birth" or "adopted", only families linked with
+// one of them are returned. A link without a pedigree counts as "birth".
+func (i *IndividualRecord) ParentFamilies(pedigrees ...string) []*FamilyRecord {
+	var fams []*FamilyRecord
+	for _, l := range i.Parents {
+		if l.Family == nil {
+			continue
+		}
+		if len(pedigrees) > 0 && !hasPedigree(l.Pedigree, pedigrees) {
+			continue
+		}
+		fams = append(fams, l.Family)
+	}
+	return fams
+}
+
+func hasPedigree(pedigree string, pedigrees []string) bool {
+	for _, p := range pedigrees {
+		if strings.EqualFold(p, pedigree) || (isBirthPedigree(p) && isBirthPedigree(pedigree)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Father returns the individual's biological father, or nil if none is known.
+func (i *IndividualRecord) Father() *IndividualRecord {
+	for _, l := range i.Parents {
+		if l.Family == nil || l.Family.Husband == nil || !isBirthPedigree(l.Pedigree) {
+			continue
+		}
+		if c := l.Family.childRecord(i); c != nil && !isBirthPedigree(c.FatherRelation) {
+			continue
+		}
+		return l.Family.Husband
+	}
+	return nil
+}
+
+// Mother returns the individual's biological mother, or nil if none is known.
+func (i *IndividualRecord) Mother() *IndividualRecord {
+	for _, l := range i.Parents {
+		if l.Family == nil || l.Family.Wife == nil || !isBirthPedigree(l.Pedigree) {
+			continue
+		}
+		if c := l.Family.childRecord(i); c != nil && !isBirthPedigree(c.MotherRelation) {
+			continue
+		}
+		return l.Family.Wife
+	}
+	return nil
+}
+
+// Spouses returns the other partner of each family in which the individual is
+// a spouse.
+func (i *IndividualRecord) Spouses() []*IndividualRecord {
+	var people []*IndividualRecord
+	for _, l := range i.Family {
+		if l.Family == nil {
+			continue
+		}
+		s := l.Family.Husband
+		if s == i {
+			s = l.Family.Wife
+		}
+		people = appendPerson(people, s)
+	}
+	return people
+}
+
+// Children returns the children of every family in which the individual is a
+// spouse, whatever their pedigree.
+func (i *IndividualRecord) Children() []*IndividualRecord {
+	var people []*IndividualRecord
+	for _, l := range i.Family {
+		if l.Family == nil {
+			continue
+		}
+		for _, c := range l.Family.Children() {
+			people = appendPerson(people, c)
+		}
+	}
+	return people
+}
+
+// Siblings returns the other children of every family in which the individual
+// is a child, including half, step and adoptive siblings.
+func (i *IndividualRecord) Siblings() []*IndividualRecord {
+	var people []*IndividualRecord
+	for _, f := range i.ParentFamilies() {
+		for _, c := range f.Children() {
+			if c != i {
+				people = appendPerson(people, c)
+			}
+		}
+	}
+	return people
+}
+
+// Children returns the children of the family.
+func (f *FamilyRecord) Children() []*IndividualRecord {
+	var people []*IndividualRecord
+	for _, c := range f.Child {
+		people = appendPerson(people, c.Person)
+	}
+	return people
+}
+
+// childRecord returns the family's record of child i, if any.
+func (f *FamilyRecord) childRecord(i *IndividualRecord) *ChildRecord {
+	for _, c := range f.Child {
+		if c.Person == i {
+			return c
+		}
+	}
+	return nil
+}
+
+// appendPerson adds p to people unless it is nil or already present.
+func appendPerson(people []*IndividualRecord, p *IndividualRecord) []*IndividualRecord {
+	if p == nil {
+		return people
+	}
+	for _, q := range people {
+		if q == p {
+			return people
+		}
+	}
+	return append(people, p)
+}
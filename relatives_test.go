@@ -0,0 +1,71 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestRelatives(t *testing.T) {
+
+	ag := decodeFile(t, "testdata/allged.ged")
+	p1 := ag.Individual[0]
+
+	intTestCases{
+		{"Parent family count was [%d]", 2, len(p1.ParentFamilies())},
+		{"Adoptive family count was [%d]", 1, len(p1.ParentFamilies("adopted"))},
+		{"Spouse count was [%d]", 2, len(p1.Spouses())},
+		{"Child count was [%d]", 3, len(p1.Children())},
+		{"Sibling count of child was [%d]", 1, len(p1.Children()[0].Siblings())},
+		{"Sibling count was [%d]", 0, len(p1.Siblings())},
+	}.run(t)
+
+	boolTestCases{
+		{"Mother is unknown", true, p1.Mother() == nil},
+	}.run(t)
+
+	stringTestCases{
+		{"Father", "PERSON5", p1.Father().Xref},
+		{"Birth family", "PARENTS", p1.ParentFamilies("birth")[0].Xref},
+		{"Spouse 0", "PERSON2", p1.Spouses()[0].Xref},
+		{"Spouse 1", "PERSON8", p1.Spouses()[1].Xref},
+		{"Child 2", "PERSON7", p1.Children()[2].Xref},
+		{"Sibling of child", "PERSON4", p1.Children()[0].Siblings()[0].Xref},
+		{"Family child", "PERSON3", ag.Family[0].Children()[0].Xref},
+	}.run(t)
+}
+
+func TestReferences(t *testing.T) {
+
+	ag := decodeFile(t, "testdata/allged.ged")
+	source := ag.Source[0]
+	var note4, note1 *NoteRecord
+	for _, n := range ag.Note {
+		switch n.Xref {
+		case "NOTE1":
+			note1 = n
+		case "NOTE4":
+			note4 = n
+		}
+	}
+
+	if n := len(ag.CitationsOf(source)); n < 30 {
+		t.Errorf("Source citation count was [%d], expected at least 30", n)
+	}
+
+	refs := ag.ReferencesTo(note4)
+	intTestCases{
+		{"NOTE4 reference count was [%d]", 1, len(refs)},
+		{"NOTE1 reference count was [%d]", 1, len(ag.ReferencesTo(note1))},
+		{"M795 reference count was [%d]", 3, len(ag.ReferencesToObject(ag.Object[1]))},
+	}.run(t)
+
+	if e, ok := refs[0].(*EventRecord); !ok || e.Tag != "MARR" {
+		t.Errorf("NOTE4 was referenced by [%#v], expected the marriage event", refs[0])
+	}
+	if f, ok := ag.ReferencesTo(note1)[0].(*FamilyRecord); !ok || f.Xref != "ADOPTIVE_PARENTS" {
+		t.Errorf("NOTE1 was referenced by [%#v], expected family ADOPTIVE_PARENTS", ag.ReferencesTo(note1)[0])
+	}
+}
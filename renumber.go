@@ -0,0 +1,193 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RenumberOrder is the order in which Renumber numbers individuals.
+type RenumberOrder int
+
+// The orders in which Renumber can number individuals.
+const (
+	FileOrder       RenumberOrder = iota // as they appear in the file
+	SurnameOrder                         // by surname, given name and birth date
+	AhnentafelOrder                      // the root person, then their birth ancestors by Ahnentafel number, other ancestors, then the rest in file order
+)
+
+// Scheme controls how Renumber numbers records.
+type Scheme struct {
+	Order RenumberOrder
+	Root  *IndividualRecord // the first person in AhnentafelOrder
+}
+
+// Renumber gives every record of g an xref made of the usual prefix for its
+// kind, such as I for individuals and F for families, and its position among
+// records of that kind, starting from 1. Individuals are numbered in the
+// order chosen by s, and families in the order of their first numbered
+// spouse, or failing that child. Other records keep the order of the file.
+// Renumber returns the new xref of each record by its old.
+func Renumber(g *Gedcom, s Scheme) (map[string]string, error) {
+	var people []*IndividualRecord
+	switch s.Order {
+	case FileOrder:
+		people = g.Individual
+	case SurnameOrder:
+		people = surnameOrder(g.Individual)
+	case AhnentafelOrder:
+		if s.Root == nil || !g.hasIndividual(s.Root) {
+			return nil, fmt.Errorf("ordering by Ahnentafel number needs a root individual in the file")
+		}
+		people = ahnentafelOrder(g.Individual, s.Root)
+	default:
+		return nil, fmt.Errorf("unknown renumbering order %d", s.Order)
+	}
+
+	rank := make(map[*IndividualRecord]int, len(people))
+	for n, i := range people {
+		rank[i] = n
+	}
+	order := map[reflect.Type][]reflect.Value{
+		reflect.TypeOf(&IndividualRecord{}): valuesOf(people),
+		reflect.TypeOf(&FamilyRecord{}):     valuesOf(familyOrder(g.Family, rank)),
+	}
+
+	var recs []reflect.Value
+	for _, rec := range g.xrefRecords() {
+		if _, ordered := order[rec.Type()]; !ordered {
+			recs = append(recs, rec)
+		}
+	}
+	for _, t := range []reflect.Type{reflect.TypeOf(&IndividualRecord{}), reflect.TypeOf(&FamilyRecord{})} {
+		recs = append(recs, order[t]...)
+	}
+
+	renumbered := make(map[string]string)
+	next := make(map[reflect.Type]int)
+	for _, rec := range recs {
+		x := recordXref(rec)
+		if x == "" {
+			continue
+		}
+		next[rec.Type()]++
+		nx := xrefPrefixes[rec.Type()] + strconv.Itoa(next[rec.Type()])
+		setXref(rec, nx)
+		renumbered[x] = nx
+	}
+
+	for _, src := range g.Source {
+		src.Repository = renumberPointers(src.Repository, renumbered)
+		src.Submitter = renumberPointers(src.Submitter, renumbered)
+	}
+	return renumbered, nil
+}
+
+// surnameOrder returns people sorted by surname, given name and birth date,
+// undated births last.
+func surnameOrder(people []*IndividualRecord) []*IndividualRecord {
+	type key struct {
+		surname, given string
+		born           time.Time
+	}
+	keys := make(map[*IndividualRecord]key, len(people))
+	for _, i := range people {
+		var k key
+		if len(i.Name) > 0 {
+			k.surname, k.given = foldName(i.Name[0].Surname()), foldName(i.Name[0].Given())
+		}
+		k.born = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+		if b := i.birth(); b != nil && b.Date != "" {
+			k.born = b.SortDate
+		}
+		keys[i] = k
+	}
+
+	sorted := append([]*IndividualRecord(nil), people...)
+	sort.SliceStable(sorted, func(j, k int) bool {
+		a, b := keys[sorted[j]], keys[sorted[k]]
+		switch {
+		case a.surname != b.surname:
+			return a.surname < b.surname
+		case a.given != b.given:
+			return a.given < b.given
+		}
+		return a.born.Before(b.born)
+	})
+	return sorted
+}
+
+// ahnentafelOrder returns root and their birth ancestors by Ahnentafel
+// number, each at their lowest, then the ancestors reached through adoptive
+// and other parents, nearest first, followed by the rest of people in their
+// order.
+func ahnentafelOrder(people []*IndividualRecord, root *IndividualRecord) []*IndividualRecord {
+	var sorted []*IndividualRecord
+	for _, gen := range newBirthPedigree(root).generations(root, 0) {
+		ancestors := make([]*IndividualRecord, 0, len(gen))
+		for i := range gen {
+			ancestors = append(ancestors, i)
+		}
+		sort.Slice(ancestors, func(j, k int) bool {
+			a, b := gen[ancestors[j]].ahnentafel, gen[ancestors[k]].ahnentafel
+			if a != b {
+				return a < b
+			}
+			return ancestors[j].Xref < ancestors[k].Xref
+		})
+		for _, i := range ancestors {
+			sorted = appendPerson(sorted, i)
+		}
+	}
+	WalkAncestors(root, 0, &TraversalOptions{Distinct: true}, func(step *LineageStep) bool {
+		sorted = appendPerson(sorted, step.Person)
+		return true
+	})
+	for _, i := range people {
+		sorted = appendPerson(sorted, i)
+	}
+	return sorted
+}
+
+// familyOrder returns families sorted by the lowest rank of a spouse, or of a
+// child for families without spouses.
+func familyOrder(families []*FamilyRecord, rank map[*IndividualRecord]int) []*FamilyRecord {
+	lowest := make(map[*FamilyRecord]int, len(families))
+	for _, f := range families {
+		lowest[f] = len(rank)
+		members := []*IndividualRecord{f.Husband, f.Wife}
+		if f.Husband == nil && f.Wife == nil {
+			members = f.Children()
+		}
+		for _, i := range members {
+			if r, ok := rank[i]; ok && r < lowest[f] {
+				lowest[f] = r
+			}
+		}
+	}
+
+	sorted := append([]*FamilyRecord(nil), families...)
+	sort.SliceStable(sorted, func(j, k int) bool {
+		return lowest[sorted[j]] < lowest[sorted[k]]
+	})
+	return sorted
+}
+
+// valuesOf returns the elements of the slice list as reflect values.
+func valuesOf(list interface{}) []reflect.Value {
+	v := reflect.ValueOf(list)
+	values := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if !v.Index(i).IsNil() {
+			values = append(values, v.Index(i))
+		}
+	}
+	return values
+}
@@ -0,0 +1,78 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRenumberFileOrder(t *testing.T) {
+	rg := decodeFile(t, "testdata/renumber.ged")
+
+	renumbered, err := Renumber(rg, Scheme{})
+	if err != nil {
+		t.Fatalf("Renumber gave error %v, expected no error", err)
+	}
+
+	stringTestCases{
+		{"Mapping", "map[FAM9:F1 IND_45:I2 P123:I1 REP7:R1 SRC3:S1]", fmt.Sprint(renumbered)},
+		{"Child", "I2", xrefs(rg.Family[0].Children())},
+		{"Citation", "S1", rg.Individual[0].Event[0].Citation[0].Source.Xref},
+		{"Repository", "@R1@", rg.Source[0].Repository[0]},
+	}.run(t)
+}
+
+func TestRenumberOrders(t *testing.T) {
+	rg := decodeFile(t, "testdata/ours.ged")
+
+	renumbered, err := Renumber(rg, Scheme{Order: SurnameOrder})
+	if err != nil {
+		t.Fatalf("Renumber gave error %v, expected no error", err)
+	}
+	stringTestCases{
+		{"Surname order", "map[F1:F1 I1:I3 I2:I1 I3:I2 N1:N1 R1:R1 S1:S1 U1:U1]", fmt.Sprint(renumbered)},
+		{"Mary", "I1", rg.Individual[1].Xref},
+	}.run(t)
+
+	root := rg.Individual[2]
+	renumbered, err = Renumber(rg, Scheme{Order: AhnentafelOrder, Root: root})
+	if err != nil {
+		t.Fatalf("Renumber gave error %v, expected no error", err)
+	}
+	stringTestCases{
+		{"Ahnentafel order", "map[F1:F1 I1:I3 I2:I1 I3:I2 N1:N1 R1:R1 S1:S1 U1:U1]", fmt.Sprint(renumbered)},
+		{"Root", "I1", root.Xref},
+		{"Father", "I2", root.Father().Xref},
+		{"Mother", "I3", root.Mother().Xref},
+	}.run(t)
+
+	if _, err := Renumber(rg, Scheme{Order: AhnentafelOrder}); err == nil {
+		t.Fatalf("Renumber without a root gave no error, expected an error")
+	}
+}
+
+func TestRenumberAhnentafelAdopted(t *testing.T) {
+	adoptive := &FamilyRecord{Xref: "F1", Husband: &IndividualRecord{Xref: "A1"}, Wife: &IndividualRecord{Xref: "A2"}}
+	birth := &FamilyRecord{Xref: "F2", Husband: &IndividualRecord{Xref: "B1"}, Wife: &IndividualRecord{Xref: "B2"}}
+	root := &IndividualRecord{Xref: "R", Parents: []*FamilyLinkRecord{
+		{Family: adoptive, Pedigree: "adopted"},
+		{Family: birth},
+	}}
+	adoptive.Child = []*ChildRecord{{Person: root}}
+	birth.Child = []*ChildRecord{{Person: root}}
+	rg := &Gedcom{
+		Individual: []*IndividualRecord{adoptive.Husband, adoptive.Wife, birth.Husband, birth.Wife, root},
+		Family:     []*FamilyRecord{adoptive, birth},
+	}
+
+	renumbered, err := Renumber(rg, Scheme{Order: AhnentafelOrder, Root: root})
+	if err != nil {
+		t.Fatalf("Renumber gave error %v, expected no error", err)
+	}
+	stringTestCases{
+		{"Ahnentafel order", "map[A1:I4 A2:I5 B1:I2 B2:I3 F1:F2 F2:F1 R:I1]", fmt.Sprint(renumbered)},
+	}.run(t)
+}
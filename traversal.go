@@ -0,0 +1,185 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strconv"
+)
+
+// TraversalOptions controls a walk through ancestors or descendants.
+type TraversalOptions struct {
+	// Pedigree restricts the walk to parent-child links with one of these
+	// pedigrees, such as "birth" or "adopted". Links without a pedigree count
+	// as "birth". All links are followed when Pedigree is empty.
+	Pedigree []string
+
+	// Distinct reports each person only once, at their first occurrence,
+	// rather than once per line of descent when a pedigree collapses.
+	Distinct bool
+}
+
+// LineageStep is one person reached in an ancestor or descendant walk.
+type LineageStep struct {
+	Person     *IndividualRecord
+	Generation int
+	Path       []*IndividualRecord // from the starting person to Person
+	Ahnentafel uint64              // ancestors only; zero off the birth line or beyond 63 generations
+	Aboville   string              // descendants only
+}
+
+// WalkAncestors calls fn for ind and each of its ancestors in order of
+// generation, up to maxGen generations back, stopping early if fn returns
+// false. A maxGen of zero or less means no limit. Ancestors that appear on
+// several lines are reported on each unless opts.Distinct is set, but a
+// person is never reported as their own ancestor. Only birth fathers and
+// mothers are given Ahnentafel numbers, so that no two lines share one;
+// adoptive and other parents, and their ancestors, are numbered zero.
+func WalkAncestors(ind *IndividualRecord, maxGen int, opts *TraversalOptions, fn func(*LineageStep) bool) {
+	if opts == nil {
+		opts = &TraversalOptions{}
+	}
+
+	seen := map[*IndividualRecord]bool{ind: true}
+	queue := []*LineageStep{{Person: ind, Path: []*IndividualRecord{ind}, Ahnentafel: 1}}
+
+	for len(queue) > 0 {
+		step := queue[0]
+		queue = queue[1:]
+		if !fn(step) {
+			return
+		}
+		if maxGen > 0 && step.Generation >= maxGen {
+			continue
+		}
+
+		for _, l := range step.Person.Parents {
+			if l.Family == nil || (len(opts.Pedigree) > 0 && !hasPedigree(l.Pedigree, opts.Pedigree)) {
+				continue
+			}
+			for n, parent := range []*IndividualRecord{l.Family.Husband, l.Family.Wife} {
+				if parent == nil || onPath(step.Path, parent) || (opts.Distinct && seen[parent]) {
+					continue
+				}
+				seen[parent] = true
+
+				var number uint64
+				if step.Ahnentafel != 0 && step.Ahnentafel < 1<<62 && isBirthParent(step.Person, parent, n) {
+					number = step.Ahnentafel*2 + uint64(n)
+				}
+				queue = append(queue, &LineageStep{
+					Person:     parent,
+					Generation: step.Generation + 1,
+					Path:       extendPath(step.Path, parent),
+					Ahnentafel: number,
+				})
+			}
+		}
+	}
+}
+
+// isBirthParent reports whether parent is the birth father of child, for n
+// zero, or the birth mother, for n one.
+func isBirthParent(child *IndividualRecord, parent *IndividualRecord, n int) bool {
+	if n == 0 {
+		return child.Father() == parent
+	}
+	return child.Mother() == parent
+}
+
+// Ancestors returns ind and its ancestors up to maxGen generations back, as
+// reported by WalkAncestors.
+func Ancestors(ind *IndividualRecord, maxGen int, opts *TraversalOptions) []*LineageStep {
+	var steps []*LineageStep
+	WalkAncestors(ind, maxGen, opts, func(s *LineageStep) bool {
+		steps = append(steps, s)
+		return true
+	})
+	return steps
+}
+
+// WalkDescendants calls fn for ind and each of its descendants in order of
+// generation, up to maxGen generations down, stopping early if fn returns
+// false. A maxGen of zero or less means no limit. Children of all of a
+// person's families are numbered consecutively in d'Aboville numbering.
+func WalkDescendants(ind *IndividualRecord, maxGen int, opts *TraversalOptions, fn func(*LineageStep) bool) {
+	if opts == nil {
+		opts = &TraversalOptions{}
+	}
+
+	seen := map[*IndividualRecord]bool{ind: true}
+	queue := []*LineageStep{{Person: ind, Path: []*IndividualRecord{ind}, Aboville: "1"}}
+
+	for len(queue) > 0 {
+		step := queue[0]
+		queue = queue[1:]
+		if !fn(step) {
+			return
+		}
+		if maxGen > 0 && step.Generation >= maxGen {
+			continue
+		}
+
+		n := 0
+		for _, l := range step.Person.Family {
+			if l.Family == nil {
+				continue
+			}
+			for _, child := range l.Family.Children() {
+				if len(opts.Pedigree) > 0 && !hasPedigree(childPedigree(child, l.Family), opts.Pedigree) {
+					continue
+				}
+				n++
+				if onPath(step.Path, child) || (opts.Distinct && seen[child]) {
+					continue
+				}
+				seen[child] = true
+
+				queue = append(queue, &LineageStep{
+					Person:     child,
+					Generation: step.Generation + 1,
+					Path:       extendPath(step.Path, child),
+					Aboville:   step.Aboville + "." + strconv.Itoa(n),
+				})
+			}
+		}
+	}
+}
+
+// Descendants returns ind and its descendants up to maxGen generations down,
+// as reported by WalkDescendants.
+func Descendants(ind *IndividualRecord, maxGen int, opts *TraversalOptions) []*LineageStep {
+	var steps []*LineageStep
+	WalkDescendants(ind, maxGen, opts, func(s *LineageStep) bool {
+		steps = append(steps, s)
+		return true
+	})
+	return steps
+}
+
+// childPedigree returns the pedigree with which child is linked to family f.
+func childPedigree(child *IndividualRecord, f *FamilyRecord) string {
+	for _, l := range child.Parents {
+		if l.Family == f {
+			return l.Pedigree
+		}
+	}
+	return ""
+}
+
+func onPath(path []*IndividualRecord, p *IndividualRecord) bool {
+	for _, q := range path {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+func extendPath(path []*IndividualRecord, p *IndividualRecord) []*IndividualRecord {
+	ext := make([]*IndividualRecord, len(path), len(path)+1)
+	copy(ext, path)
+	return append(ext, p)
+}
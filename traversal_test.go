@@ -0,0 +1,104 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"fmt"
+	"testing"
+)
+
+// person returns the individual in pg with the given xref.
+func person(t *testing.T, pg *Gedcom, xref string) *IndividualRecord {
+	for _, i := range pg.Individual {
+		if i.Xref == xref {
+			return i
+		}
+	}
+	t.Fatalf("Individual %s not found", xref)
+	return nil
+}
+
+func TestAncestors(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	i9 := person(t, pg, "I9")
+
+	all := Ancestors(i9, 0, nil)
+	distinct := Ancestors(i9, 0, &TraversalOptions{Distinct: true})
+	two := Ancestors(i9, 2, nil)
+
+	intTestCases{
+		{"Ancestor step count was [%d]", 11, len(all)},
+		{"Distinct ancestor step count was [%d]", 9, len(distinct)},
+		{"Two generation step count was [%d]", 7, len(two)},
+		{"Great-grandfather generation was [%d]", 3, all[7].Generation},
+		{"Great-grandfather path length was [%d]", 4, len(all[7].Path)},
+		{"Loop ancestor step count was [%d]", 2, len(Ancestors(person(t, pg, "I12"), 0, nil))},
+	}.run(t)
+
+	var numbers string
+	for _, s := range all {
+		numbers += fmt.Sprintf("%s=%d ", s.Person.Xref, s.Ahnentafel)
+	}
+
+	stringTestCases{
+		{"Ahnentafel numbers", "I9=1 I7=2 I8=3 I3=4 I5=5 I6=6 I4=7 I1=8 I2=9 I1=14 I2=15 ", numbers},
+	}.run(t)
+
+	adopted := Ancestors(person(t, pg, "I10"), 0, &TraversalOptions{Pedigree: []string{"birth"}})
+	intTestCases{
+		{"Birth ancestors of adopted child was [%d]", 1, len(adopted)},
+	}.run(t)
+
+	numbers = ""
+	for _, s := range Ancestors(person(t, pg, "I10"), 1, nil) {
+		numbers += fmt.Sprintf("%s=%d ", s.Person.Xref, s.Ahnentafel)
+	}
+
+	// PERSON1 has a birth father and an adoptive mother.
+	ag := decodeFile(t, "testdata/allged.ged")
+	var adoptive string
+	for _, s := range Ancestors(person(t, ag, "PERSON1"), 0, nil) {
+		adoptive += fmt.Sprintf("%s=%d ", s.Person.Xref, s.Ahnentafel)
+	}
+
+	stringTestCases{
+		{"Adopted child's Ahnentafel numbers", "I10=1 I7=0 I8=0 ", numbers},
+		{"Adoptive parent's Ahnentafel numbers", "PERSON1=1 PERSON5=2 PERSON6=0 ", adoptive},
+	}.run(t)
+}
+
+func TestDescendants(t *testing.T) {
+
+	pg := decodeFile(t, "testdata/pedigree.ged")
+	i1 := person(t, pg, "I1")
+
+	all := Descendants(i1, 0, nil)
+	birth := Descendants(i1, 0, &TraversalOptions{Pedigree: []string{"birth"}, Distinct: true})
+
+	var numbers string
+	for _, s := range all {
+		numbers += fmt.Sprintf("%s=%s ", s.Person.Xref, s.Aboville)
+	}
+
+	stringTestCases{
+		{"d'Aboville numbers", "I1=1 I3=1.1 I4=1.2 I7=1.1.1 I8=1.2.1 I9=1.1.1.1 I10=1.1.1.2 I9=1.2.1.1 I10=1.2.1.2 ", numbers},
+	}.run(t)
+
+	intTestCases{
+		{"Distinct birth descendant count was [%d]", 6, len(birth)},
+		{"One generation descendant count was [%d]", 3, len(Descendants(i1, 1, nil))},
+	}.run(t)
+
+	var stopped int
+	WalkDescendants(i1, 0, nil, func(s *LineageStep) bool {
+		stopped++
+		return s.Generation < 1
+	})
+
+	intTestCases{
+		{"Steps before stopping was [%d]", 2, stopped},
+	}.run(t)
+}
@@ -18,7 +18,9 @@ type Decoder struct {
 	cbUnrecognizedTag func(int, string, string, string)
 }
 
-// Gedcom is the top level structure.
+// Gedcom is the top level structure. Place indexes the places of events:
+// Decode builds it and the editing, merging and Apply functions keep it up
+// to date, but changes made directly to records do not.
 type Gedcom struct {
 	Header     *HeaderRecord
 	Submission *SubmissionRecord
@@ -29,7 +31,10 @@ type Gedcom struct {
 	Repository []*RepositoryRecord
 	Source     []*SourceRecord
 	Note       []*NoteRecord
+	Place      []*Place
 	Trailer    *Trailer
+
+	refs *referenceIndex
 }
 
 // AddressRecord describes and address.
@@ -44,6 +49,14 @@ type AddressRecord struct {
 	Phone      string
 }
 
+// AssociationRecord links an individual or event to an associated person.
+type AssociationRecord struct {
+	Person   *IndividualRecord
+	Relation string
+	Citation []*CitationRecord
+	Note     []*NoteRecord
+}
+
 // ChangedRecord describes a document change.
 type ChangedRecord struct {
 	Stamp *TimestampRecord
@@ -88,21 +101,28 @@ type EncodingRecord struct {
 
 // EventRecord describes a life event.
 type EventRecord struct {
-	Tag        string
-	Value      string
-	Type       string
-	Date       string
-	SortDate   time.Time
-	Place      PlaceRecord
-	Address    AddressRecord
-	Age        string
-	Agency     string
-	Citation   []*CitationRecord
-	Object     []*ObjectRecord
-	Note       []*NoteRecord
-	Cause      []*NoteRecord
-	Parents    []*FamilyLinkRecord
-	SpouseInfo []*SpouseInfoRecord
+	Tag         string
+	Value       string
+	Type        string
+	Date        string
+	SortDate    time.Time
+	SDate       string
+	Place       PlaceRecord
+	Address     AddressRecord
+	Phone       []string
+	Email       []string
+	Website     []string
+	Age         string
+	Agency      string
+	Religion    string
+	Restriction string
+	Citation    []*CitationRecord
+	Object      []*ObjectRecord
+	Note        []*NoteRecord
+	Cause       []*NoteRecord
+	Parents     []*FamilyLinkRecord
+	SpouseInfo  []*SpouseInfoRecord
+	Association []*AssociationRecord
 }
 
 // FamilyLinkRecord ...
@@ -116,22 +136,26 @@ type FamilyLinkRecord struct {
 // FamilyRecord describes a family unit.
 type FamilyRecord struct {
 	Xref             string
+	UID              []string
+	UserReference    []string
 	Husband          *IndividualRecord
 	Wife             *IndividualRecord
 	NumberOfChildren *EventRecord
 	Changed          *ChangedRecord
 	Child            []*ChildRecord
 	Event            []*EventRecord
+	Restriction      string
 	Citation         []*CitationRecord
 	Object           []*ObjectRecord
 	Note             []*NoteRecord
 }
 
-// FileRecord ...
+// FileRecord describes a single file belonging to a multimedia object.
 type FileRecord struct {
 	Name        string
 	Title       string
 	Form        string
+	MediaType   string
 	Description *NoteRecord
 }
 
@@ -161,6 +185,7 @@ type HeaderRecord struct {
 	Submitter   *SubmitterRecord
 	Submission  *SubmissionRecord
 	Info        *HeaderInfoRecord
+	PlaceForm   string
 	Note        *NoteRecord
 }
 
@@ -176,18 +201,22 @@ type HeaderSourceRecord struct {
 
 // IndividualRecord describes a single person.
 type IndividualRecord struct {
-	Xref      string
-	Sex       string
-	Changed   *ChangedRecord
-	Photo     *ObjectRecord
-	Name      []*NameRecord
-	Event     []*EventRecord
-	Attribute []*EventRecord
-	Parents   []*FamilyLinkRecord
-	Family    []*FamilyLinkRecord
-	Citation  []*CitationRecord
-	Object    []*ObjectRecord
-	Note      []*NoteRecord
+	Xref          string
+	UID           []string
+	UserReference []string
+	Sex           string
+	Changed       *ChangedRecord
+	Photo         *ObjectRecord
+	Name          []*NameRecord
+	Event         []*EventRecord
+	Attribute     []*EventRecord
+	Parents       []*FamilyLinkRecord
+	Family        []*FamilyLinkRecord
+	Association   []*AssociationRecord
+	Restriction   string
+	Citation      []*CitationRecord
+	Object        []*ObjectRecord
+	Note          []*NoteRecord
 }
 
 // NameRecord describes a person's name.
@@ -206,25 +235,47 @@ type NoteRecord struct {
 	Citation []*CitationRecord
 }
 
-// ObjectRecord describes a source object.
+// ObjectRecord describes a multimedia object. Files may be linked using
+// FILE or, in GEDCOM 5.5, embedded as BLOB data.
 type ObjectRecord struct {
-	Xref string
-	File *FileRecord
-	Note []*NoteRecord
+	Xref    string
+	Form    string
+	Title   string
+	File    []*FileRecord
+	Blob    []byte
+	Changed *ChangedRecord
+	Note    []*NoteRecord
 }
 
 // PlaceRecord describes a location.
 type PlaceRecord struct {
 	Name      string
+	Form      string
 	Latitude  string
 	Longitude string
+	Phonetic  []*PlaceVariantRecord
+	Romanized []*PlaceVariantRecord
+	Place     *Place
 	Citation  []*CitationRecord
 	Note      []*NoteRecord
 }
 
-// RepositoryRecord is currently not implemented.
+// PlaceVariantRecord describes a phonetic or romanized variation of a place name.
+type PlaceVariantRecord struct {
+	Name string
+	Type string
+}
+
+// RepositoryRecord describes a repository of sources, such as an archive or library.
 type RepositoryRecord struct {
-	Xref string
+	Xref    string
+	Name    string
+	Address *AddressRecord
+	Phone   []string
+	Email   []string
+	Website []string
+	Changed *ChangedRecord
+	Note    []*NoteRecord
 }
 
 // SourceDataRecord describes events pertaining to this source
@@ -236,31 +287,33 @@ type SourceDataRecord struct {
 
 // SourceRecord describes a single source document.
 type SourceRecord struct {
-	Xref        string
-	Author      string
-	Title       string
-	Abbr        string
-	Publication string
-	Type        string
-	Text        string
-	MediaType   string
-	Periodical  string
-	Volume      string
-	Page        []string
-	Film        []string
-	File        []string
-	FileNumber  []string
-	Place       []string
-	Date        []string
-	DateViewed  []string
-	URL         []string
-	DocLocation []string
-	Repository  []string
-	Submitter   []string
-	Changed     *ChangedRecord
-	EventData   *SourceDataRecord
-	Note        []*NoteRecord
-	Object      []*ObjectRecord
+	Xref          string
+	UID           []string
+	UserReference []string
+	Author        string
+	Title         string
+	Abbr          string
+	Publication   string
+	Type          string
+	Text          string
+	MediaType     string
+	Periodical    string
+	Volume        string
+	Page          []string
+	Film          []string
+	File          []string
+	FileNumber    []string
+	Place         []string
+	Date          []string
+	DateViewed    []string
+	URL           []string
+	DocLocation   []string
+	Repository    []string
+	Submitter     []string
+	Changed       *ChangedRecord
+	EventData     *SourceDataRecord
+	Note          []*NoteRecord
+	Object        []*ObjectRecord
 }
 
 // SpouseInfoRecord describes information about a spouse referenced in a family event.
@@ -0,0 +1,245 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"time"
+)
+
+// Names of the rules applied by Validate.
+const (
+	RuleEventAfterDeath        = "EventAfterDeath"
+	RuleBirthBeforeParentBirth = "BirthBeforeParentBirth"
+	RuleMotherAge              = "MotherAge"
+	RuleMarriageAge            = "MarriageAge"
+	RuleSpouseSex              = "SpouseSex"
+	RuleBurialBeforeDeath      = "BurialBeforeDeath"
+	RulePosthumousBirth        = "PosthumousBirth"
+	RuleLifespan               = "Lifespan"
+)
+
+// Rules selects the consistency checks made by Validate and their thresholds.
+type Rules struct {
+	EventAfterDeath        bool // events other than burial, cremation and probate after death
+	BirthBeforeParentBirth bool // children born before a parent
+	MotherAge              bool // mothers younger than MinMotherAge or older than MaxMotherAge at a birth
+	MarriageAge            bool // spouses younger than MinMarriageAge at marriage
+	SpouseSex              bool // husbands recorded as female or wives as male
+	BurialBeforeDeath      bool // burial or cremation before death
+	PosthumousBirth        bool // births more than MaxPosthumousMonths after the father's death
+	Lifespan               bool // lives longer than MaxLifespan years
+
+	MinMotherAge        int
+	MaxMotherAge        int
+	MinMarriageAge      int
+	MaxPosthumousMonths int
+	MaxLifespan         int
+}
+
+// DefaultRules returns a rule set with every check enabled and conventional
+// thresholds.
+func DefaultRules() Rules {
+	return Rules{
+		EventAfterDeath:        true,
+		BirthBeforeParentBirth: true,
+		MotherAge:              true,
+		MarriageAge:            true,
+		SpouseSex:              true,
+		BurialBeforeDeath:      true,
+		PosthumousBirth:        true,
+		Lifespan:               true,
+
+		MinMotherAge:        12,
+		MaxMotherAge:        55,
+		MinMarriageAge:      12,
+		MaxPosthumousMonths: 9,
+		MaxLifespan:         120,
+	}
+}
+
+// Finding is a single problem reported by Validate.
+type Finding struct {
+	Rule    string
+	Xref    string // the individual or family at fault
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Xref, f.Message, f.Rule)
+}
+
+// Validate checks g for genealogically implausible data. Dates are compared
+// as ranges, so a finding is only reported when every reading of the dates
+// involved breaks the rule.
+func Validate(g *Gedcom, rules Rules) []Finding {
+	var findings []Finding
+	report := func(rule string, xref string, format string, args ...interface{}) {
+		findings = append(findings, Finding{Rule: rule, Xref: xref, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, i := range g.Individual {
+		birth, hasBirth := i.eventRange("BIRT")
+		death, hasDeath := i.eventRange("DEAT")
+
+		if rules.EventAfterDeath && hasDeath {
+			for _, e := range append(i.Event, i.Attribute...) {
+				switch e.Tag {
+				case "DEAT", "BURI", "CREM", "PROB":
+					continue
+				}
+				if r, ok := dateRange(e); ok && definitelyBefore(death, r) {
+					report(RuleEventAfterDeath, i.Xref, "%s on %s is after death on %s", e.Tag, e.Date, i.eventDate("DEAT"))
+				}
+			}
+		}
+
+		if rules.BurialBeforeDeath && hasDeath {
+			for _, tag := range []string{"BURI", "CREM"} {
+				if r, ok := i.eventRange(tag); ok && definitelyBefore(r, death) {
+					report(RuleBurialBeforeDeath, i.Xref, "%s on %s is before death on %s", tag, i.eventDate(tag), i.eventDate("DEAT"))
+				}
+			}
+		}
+
+		if rules.Lifespan && hasBirth && hasDeath && !birth.Latest.IsZero() && !death.Earliest.IsZero() {
+			if age := ageBetween(birth.Latest, death.Earliest); age.Years > rules.MaxLifespan {
+				report(RuleLifespan, i.Xref, "lived at least %d years", age.Years)
+			}
+		}
+
+		if rules.BirthBeforeParentBirth && hasBirth {
+			for _, f := range i.ParentFamilies() {
+				for _, p := range []*IndividualRecord{f.Husband, f.Wife} {
+					if p == nil {
+						continue
+					}
+					if pb, ok := p.eventRange("BIRT"); ok && definitelyBefore(birth, pb) {
+						report(RuleBirthBeforeParentBirth, i.Xref, "born %s, before parent %s born %s", i.eventDate("BIRT"), p.Xref, p.eventDate("BIRT"))
+					}
+				}
+			}
+		}
+	}
+
+	for _, f := range g.Family {
+		if rules.SpouseSex {
+			if f.Husband != nil && f.Husband.Sex == "F" {
+				report(RuleSpouseSex, f.Xref, "husband %s is female", f.Husband.Xref)
+			}
+			if f.Wife != nil && f.Wife.Sex == "M" {
+				report(RuleSpouseSex, f.Xref, "wife %s is male", f.Wife.Xref)
+			}
+		}
+
+		if rules.MarriageAge {
+			for _, e := range f.Event {
+				if e.Tag != "MARR" {
+					continue
+				}
+				when, ok := dateRange(e)
+				if !ok {
+					continue
+				}
+				for _, s := range []*IndividualRecord{f.Husband, f.Wife} {
+					if s == nil {
+						continue
+					}
+					if b, ok := s.eventRange("BIRT"); ok {
+						if age, ok := oldest(b, when); ok && age.Years < rules.MinMarriageAge {
+							report(RuleMarriageAge, f.Xref, "%s married on %s aged under %d", s.Xref, e.Date, rules.MinMarriageAge)
+						}
+					}
+				}
+			}
+		}
+
+		for _, c := range f.Children() {
+			cb, ok := c.eventRange("BIRT")
+			if !ok {
+				continue
+			}
+
+			if rules.MotherAge && f.Wife != nil && isBirthPedigree(childPedigree(c, f)) {
+				if mb, ok := f.Wife.eventRange("BIRT"); ok {
+					if age, ok := oldest(mb, cb); ok && age.Years < rules.MinMotherAge {
+						report(RuleMotherAge, f.Xref, "mother %s was under %d at the birth of %s", f.Wife.Xref, rules.MinMotherAge, c.Xref)
+					}
+					if age, ok := youngest(mb, cb); ok && age.Years > rules.MaxMotherAge {
+						report(RuleMotherAge, f.Xref, "mother %s was over %d at the birth of %s", f.Wife.Xref, rules.MaxMotherAge, c.Xref)
+					}
+				}
+			}
+
+			if rules.PosthumousBirth && f.Husband != nil && isBirthPedigree(childPedigree(c, f)) {
+				if fd, ok := f.Husband.eventRange("DEAT"); ok && !fd.Latest.IsZero() && !cb.Earliest.IsZero() {
+					if cb.Earliest.After(fd.Latest.AddDate(0, rules.MaxPosthumousMonths, 0)) {
+						report(RulePosthumousBirth, f.Xref, "%s born %s, more than %d months after father %s died", c.Xref, c.eventDate("BIRT"), rules.MaxPosthumousMonths, f.Husband.Xref)
+					}
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// eventRange returns the date range of the individual's first event with tag.
+func (i *IndividualRecord) eventRange(tag string) (DateRange, bool) {
+	for _, e := range i.Event {
+		if e.Tag == tag {
+			return dateRange(e)
+		}
+	}
+	return DateRange{}, false
+}
+
+// eventDate returns the date of the individual's first event with tag.
+func (i *IndividualRecord) eventDate(tag string) string {
+	for _, e := range i.Event {
+		if e.Tag == tag {
+			return e.Date
+		}
+	}
+	return ""
+}
+
+func dateRange(e *EventRecord) (DateRange, bool) {
+	if e.Date == "" {
+		return DateRange{}, false
+	}
+	r, err := ParseDateRange(e.Date)
+	return r, err == nil
+}
+
+// definitelyBefore reports whether every day in a is before every day in b.
+func definitelyBefore(a DateRange, b DateRange) bool {
+	return !a.Latest.IsZero() && !b.Earliest.IsZero() && a.Latest.Before(b.Earliest)
+}
+
+// oldest returns the greatest possible age on a date in when for someone born
+// in birth.
+func oldest(birth DateRange, when DateRange) (Age, bool) {
+	return ageBetweenBounds(birth.Earliest, when.Latest)
+}
+
+// youngest returns the least possible age on a date in when for someone born
+// in birth.
+func youngest(birth DateRange, when DateRange) (Age, bool) {
+	return ageBetweenBounds(birth.Latest, when.Earliest)
+}
+
+// ageBetweenBounds returns the age from one date to another, or -1 years if
+// the second date is the earlier. It fails if either date is unknown.
+func ageBetweenBounds(from time.Time, to time.Time) (Age, bool) {
+	if from.IsZero() || to.IsZero() {
+		return Age{}, false
+	}
+	if to.Before(from) {
+		return Age{Years: -1}, true
+	}
+	return ageBetween(from, to), true
+}
@@ -0,0 +1,47 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+
+	vg := decodeFile(t, "testdata/invalid.ged")
+
+	var messages []string
+	for _, f := range Validate(vg, DefaultRules()) {
+		messages = append(messages, f.String())
+	}
+
+	expected := []string{
+		"I1: RESI on 1835 is after death on 12 MAR 1830 (EventAfterDeath)",
+		"I1: BURI on 1 MAR 1830 is before death on 12 MAR 1830 (BurialBeforeDeath)",
+		"I1: lived at least 129 years (Lifespan)",
+		"I3: born 1785, before parent I2 born 1790 (BirthBeforeParentBirth)",
+		"F1: wife I2 is male (SpouseSex)",
+		"F1: I2 married on 1795 aged under 12 (MarriageAge)",
+		"F1: mother I2 was under 12 at the birth of I3 (MotherAge)",
+		"F1: mother I2 was over 55 at the birth of I4 (MotherAge)",
+		"F1: I4 born 1850, more than 9 months after father I1 died (PosthumousBirth)",
+	}
+
+	stringTestCases{
+		{"Findings", strings.Join(expected, "\n"), strings.Join(messages, "\n")},
+	}.run(t)
+
+	ag := decodeFile(t, "testdata/allged.ged")
+	rules := DefaultRules()
+	rules.SpouseSex = false
+	rules.MotherAge = false
+	rules.MaxLifespan = 130
+
+	intTestCases{
+		{"Finding count with rules disabled was [%d]", 5, len(Validate(vg, rules))},
+		{"allged.ged finding count was [%d]", 2, len(Validate(ag, DefaultRules()))},
+	}.run(t)
+}
@@ -0,0 +1,106 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// xrefPrefixes gives the conventional xref prefix of each kind of record.
+var xrefPrefixes = map[reflect.Type]string{
+	reflect.TypeOf(&IndividualRecord{}): "I",
+	reflect.TypeOf(&FamilyRecord{}):     "F",
+	reflect.TypeOf(&SourceRecord{}):     "S",
+	reflect.TypeOf(&NoteRecord{}):       "N",
+	reflect.TypeOf(&ObjectRecord{}):     "O",
+	reflect.TypeOf(&RepositoryRecord{}): "R",
+	reflect.TypeOf(&SubmitterRecord{}):  "U",
+	reflect.TypeOf(&SubmissionRecord{}): "B",
+}
+
+// xrefRecords returns pointers to the top level records of g that may have an
+// xref, in the order they are held.
+func (g *Gedcom) xrefRecords() []reflect.Value {
+	var recs []reflect.Value
+	s := reflect.ValueOf(g).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		switch {
+		case f.Kind() == reflect.Ptr && hasXref(f.Type()) && !f.IsNil():
+			recs = append(recs, f)
+		case f.Kind() == reflect.Slice && hasXref(f.Type().Elem()):
+			for j := 0; j < f.Len(); j++ {
+				if !f.Index(j).IsNil() {
+					recs = append(recs, f.Index(j))
+				}
+			}
+		}
+	}
+	return recs
+}
+
+func hasXref(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	f, ok := t.Elem().FieldByName("Xref")
+	return ok && f.Type.Kind() == reflect.String
+}
+
+func setXref(rec reflect.Value, xref string) {
+	rec.Elem().FieldByName("Xref").SetString(xref)
+}
+
+// xrefPrefix returns the xref less any trailing digits, or the conventional
+// prefix for the kind of record if that leaves nothing.
+func xrefPrefix(rec reflect.Value, xref string) string {
+	p := strings.TrimRight(xref, "0123456789")
+	if p == "" {
+		p = xrefPrefixes[rec.Type()]
+	}
+	return p
+}
+
+// xrefAllocator hands out xrefs not already in use.
+type xrefAllocator struct {
+	used map[string]bool
+	next map[string]int
+}
+
+func newXrefAllocator() *xrefAllocator {
+	return &xrefAllocator{used: make(map[string]bool), next: make(map[string]int)}
+}
+
+// use marks xref as taken.
+func (a *xrefAllocator) use(xref string) {
+	a.used[xref] = true
+}
+
+// allocate returns the lowest numbered unused xref with the given prefix
+// above any allocated before.
+func (a *xrefAllocator) allocate(prefix string) string {
+	n := a.next[prefix]
+	for {
+		n++
+		x := prefix + strconv.Itoa(n)
+		if !a.used[x] {
+			a.next[prefix] = n
+			a.used[x] = true
+			return x
+		}
+	}
+}
+
+// newXref returns an xref with the given prefix that no record of g uses.
+func (g *Gedcom) newXref(prefix string) string {
+	alloc := newXrefAllocator()
+	for _, rec := range g.xrefRecords() {
+		alloc.use(recordXref(rec))
+	}
+	return alloc.allocate(prefix)
+}